@@ -5,11 +5,17 @@ package ast
 import (
 	"bytes"
 	"monkey/token"
+	"strings"
 )
 
 type Node interface {
 	TokenLiteral() string
 	String() string
+
+	// Where the first token of the node starts and where its last token ends, so the source of a
+	// node is input[Pos().Offset:End().Offset]; a semicolon ending a statement isn't part of it
+	Pos() token.Position
+	End() token.Position
 }
 
 type Statement interface {
@@ -26,6 +32,9 @@ type Program struct {
 	// Root node of every AST
 
 	Statements []Statement
+
+	// Comments attached to the statements; nil unless the parser was asked to keep comments
+	Comments CommentMap
 }
 
 func (p *Program) TokenLiteral() string {
@@ -38,6 +47,26 @@ func (p *Program) TokenLiteral() string {
 	}
 }
 
+func (p *Program) Pos() token.Position {
+	// Implements the Node interface
+
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+
+	return token.Position{}
+}
+
+func (p *Program) End() token.Position {
+	// Implements the Node interface
+
+	if len(p.Statements) > 0 {
+		return p.Statements[len(p.Statements)-1].End()
+	}
+
+	return token.Position{}
+}
+
 func (p *Program) String() string {
 	// Creates a buffer and writes the return value of each statement's String() method to it
 
@@ -54,9 +83,12 @@ type LetStatement struct {
 	// Holds the LET token, the identifier, and the expression
 	// let <name> = <value>; <=> let <identifer> = <expression>;
 	// let x = 5; => holds: LET, Identifier(IDENT, "x"), and 5
+	// let x: int = 5; => also holds TypeName(IDENT, "int")
+	// const x = 5; => holds CONST instead of LET, and the binding can't be reassigned
 
-	Token token.Token // The token.LET token
+	Token token.Token // The token.LET or token.CONST token
 	Name  *Identifier
+	Type  *TypeName // Optional annotation, nil if there is none
 	Value Expression
 }
 
@@ -69,13 +101,45 @@ func (ls *LetStatement) TokenLiteral() string {
 	return ls.Token.Literal
 }
 
+func (ls *LetStatement) Pos() token.Position {
+	// Implements the Node interface
+
+	return ls.Token.Pos
+}
+
+func (ls *LetStatement) End() token.Position {
+	// Implements the Node interface
+
+	switch {
+	case ls.Value != nil:
+		return ls.Value.End()
+	case ls.Type != nil:
+		return ls.Type.End()
+	case ls.Name != nil:
+		return ls.Name.End()
+	}
+
+	return ls.Token.EndPosition()
+}
+
+func (ls *LetStatement) IsConst() bool {
+	// Checks if the statement declares a constant
+
+	return ls.Token.Type == token.CONST
+}
+
 func (ls *LetStatement) String() string {
-	// Returns "let <name> = <value>;" as a string
+	// Returns "let <name> = <value>;" or "let <name>: <type> = <value>;" as a string
 
 	var out bytes.Buffer
 
 	out.WriteString(ls.TokenLiteral() + " ")
 	out.WriteString(ls.Name.String())
+
+	if ls.Type != nil {
+		out.WriteString(": " + ls.Type.String())
+	}
+
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
@@ -104,6 +168,22 @@ func (rs *ReturnStatement) TokenLiteral() string {
 	return rs.Token.Literal
 }
 
+func (rs *ReturnStatement) Pos() token.Position {
+	// Implements the Node interface
+
+	return rs.Token.Pos
+}
+
+func (rs *ReturnStatement) End() token.Position {
+	// Implements the Node interface
+
+	if rs.ReturnValue != nil {
+		return rs.ReturnValue.End()
+	}
+
+	return rs.Token.EndPosition()
+}
+
 func (rs *ReturnStatement) String() string {
 	// Returns "return <value>;" as a string
 
@@ -136,6 +216,22 @@ func (es *ExpressionStatement) TokenLiteral() string {
 	return es.Token.Literal
 }
 
+func (es *ExpressionStatement) Pos() token.Position {
+	// Implements the Node interface
+
+	return es.Token.Pos
+}
+
+func (es *ExpressionStatement) End() token.Position {
+	// Implements the Node interface
+
+	if es.Expression != nil {
+		return es.Expression.End()
+	}
+
+	return es.Token.EndPosition()
+}
+
 func (es *ExpressionStatement) String() string {
 	// Returns the entire expression as a string
 
@@ -163,6 +259,18 @@ func (i *Identifier) TokenLiteral() string {
 	return i.Token.Literal
 }
 
+func (i *Identifier) Pos() token.Position {
+	// Implements the Node interface
+
+	return i.Token.Pos
+}
+
+func (i *Identifier) End() token.Position {
+	// Implements the Node interface
+
+	return i.Token.EndPosition()
+}
+
 func (i *Identifier) String() string {
 	// Returns the identifier as a string
 
@@ -186,12 +294,59 @@ func (il *IntegerLiteral) TokenLiteral() string {
 	return il.Token.Literal
 }
 
+func (il *IntegerLiteral) Pos() token.Position {
+	// Implements the Node interface
+
+	return il.Token.Pos
+}
+
+func (il *IntegerLiteral) End() token.Position {
+	// Implements the Node interface
+
+	return il.Token.EndPosition()
+}
+
 func (il *IntegerLiteral) String() string {
 	// Returns the integer literal as a string
 
 	return il.Token.Literal
 }
 
+type CharLiteral struct {
+	// Holds a character literal, whose value is the character's Unicode code point
+	// 'a'; => holds: CHAR and 97
+
+	Token token.Token
+	Value rune
+}
+
+// Implements the Expression interface
+func (cl *CharLiteral) expressionNode() {}
+
+func (cl *CharLiteral) TokenLiteral() string {
+	// Implements the Node interface
+
+	return cl.Token.Literal
+}
+
+func (cl *CharLiteral) Pos() token.Position {
+	// Implements the Node interface
+
+	return cl.Token.Pos
+}
+
+func (cl *CharLiteral) End() token.Position {
+	// Implements the Node interface
+
+	return cl.Token.EndPosition()
+}
+
+func (cl *CharLiteral) String() string {
+	// Returns the character literal as written, quotes included
+
+	return "'" + cl.Token.Literal + "'"
+}
+
 type PrefixExpression struct {
 	// Holds a prefix expression
 	// -5; => holds: MINUS, "-", and 5
@@ -210,6 +365,22 @@ func (pe *PrefixExpression) TokenLiteral() string {
 	return pe.Token.Literal
 }
 
+func (pe *PrefixExpression) Pos() token.Position {
+	// Implements the Node interface
+
+	return pe.Token.Pos
+}
+
+func (pe *PrefixExpression) End() token.Position {
+	// Implements the Node interface
+
+	if pe.Right != nil {
+		return pe.Right.End()
+	}
+
+	return pe.Token.EndPosition()
+}
+
 func (pe *PrefixExpression) String() string {
 	// Returns the prefix expression as a string
 
@@ -242,6 +413,18 @@ func (ie *InfixExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+func (ie *InfixExpression) Pos() token.Position {
+	// Implements the Node interface
+
+	return ie.Left.Pos()
+}
+
+func (ie *InfixExpression) End() token.Position {
+	// Implements the Node interface
+
+	return ie.Right.End()
+}
+
 func (ie *InfixExpression) String() string {
 	// Returns the infix expression as a string
 
@@ -255,3 +438,430 @@ func (ie *InfixExpression) String() string {
 
 	return out.String()
 }
+
+type MatchExpression struct {
+	// Holds a match expression, which compares the subject against each arm's pattern in order and
+	// evaluates to the value of the first arm that matches, or to the default
+	// match (<subject>) { <pattern> => <value>, ..., _ => <default> }
+
+	Token   token.Token // The token.MATCH token
+	Subject Expression
+	Arms    []*MatchArm
+	Default Expression  // The value of the `_` arm; nil if there isn't one
+	Rbrace  token.Token // The closing } token
+}
+
+type MatchArm struct {
+	// Holds a single `<pattern> => <value>` arm of a match expression
+
+	Pattern Expression
+	Value   Expression
+}
+
+// Implements the Expression interface
+func (me *MatchExpression) expressionNode() {}
+
+func (me *MatchExpression) TokenLiteral() string {
+	// Implements the Node interface
+
+	return me.Token.Literal
+}
+
+func (me *MatchExpression) Pos() token.Position {
+	// Implements the Node interface
+
+	return me.Token.Pos
+}
+
+func (me *MatchExpression) End() token.Position {
+	// Implements the Node interface
+
+	return me.Rbrace.EndPosition()
+}
+
+func (me *MatchExpression) String() string {
+	// Returns "match (<subject>) { <pattern> => <value>, _ => <default> }" as a string
+
+	var out bytes.Buffer
+
+	arms := []string{}
+
+	for _, arm := range me.Arms {
+		arms = append(arms, arm.Pattern.String()+" => "+arm.Value.String())
+	}
+
+	if me.Default != nil {
+		arms = append(arms, "_ => "+me.Default.String())
+	}
+
+	out.WriteString("match (")
+	out.WriteString(me.Subject.String())
+	out.WriteString(") { ")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+type IndexExpression struct {
+	// Holds an index expression
+	// <expression>[<expression>]; => holds: the indexed expression, LBRACKET, the index, and ]
+
+	Token    token.Token // The token.LBRACKET token
+	Left     Expression
+	Index    Expression
+	Rbracket token.Token // The closing ] token
+}
+
+// Implements the Expression interface
+func (ie *IndexExpression) expressionNode() {}
+
+func (ie *IndexExpression) TokenLiteral() string {
+	// Implements the Node interface
+
+	return ie.Token.Literal
+}
+
+func (ie *IndexExpression) Pos() token.Position {
+	// Implements the Node interface
+
+	return ie.Left.Pos()
+}
+
+func (ie *IndexExpression) End() token.Position {
+	// Implements the Node interface
+
+	return ie.Rbracket.EndPosition()
+}
+
+func (ie *IndexExpression) String() string {
+	// Returns "(<left>[<index>])" as a string
+
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ie.Left.String())
+	out.WriteString("[")
+	out.WriteString(ie.Index.String())
+	out.WriteString("])")
+
+	return out.String()
+}
+
+type SliceExpression struct {
+	// Holds a slice expression; a missing low bound means the beginning and a missing high bound
+	// means the end
+	// <expression>[<low>:<high>]; => holds: the sliced expression, LBRACKET, low, high, and ]
+
+	Token    token.Token // The token.LBRACKET token
+	Left     Expression
+	Low      Expression  // nil if left out
+	High     Expression  // nil if left out
+	Rbracket token.Token // The closing ] token
+}
+
+// Implements the Expression interface
+func (se *SliceExpression) expressionNode() {}
+
+func (se *SliceExpression) TokenLiteral() string {
+	// Implements the Node interface
+
+	return se.Token.Literal
+}
+
+func (se *SliceExpression) Pos() token.Position {
+	// Implements the Node interface
+
+	return se.Left.Pos()
+}
+
+func (se *SliceExpression) End() token.Position {
+	// Implements the Node interface
+
+	return se.Rbracket.EndPosition()
+}
+
+func (se *SliceExpression) String() string {
+	// Returns "(<left>[<low>:<high>])" as a string
+
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+
+	if se.Low != nil {
+		out.WriteString(se.Low.String())
+	}
+
+	out.WriteString(":")
+
+	if se.High != nil {
+		out.WriteString(se.High.String())
+	}
+
+	out.WriteString("])")
+
+	return out.String()
+}
+
+type StringLiteral struct {
+	// Holds a string literal
+	// "hello"; => holds: STRING and "hello"
+	// `a "b"`; => holds: RAW_STRING and `a "b"`
+
+	Token token.Token
+	Value string
+}
+
+// Implements the Expression interface
+func (sl *StringLiteral) expressionNode() {}
+
+func (sl *StringLiteral) TokenLiteral() string {
+	// Implements the Node interface
+
+	return sl.Token.Literal
+}
+
+func (sl *StringLiteral) Pos() token.Position {
+	// Implements the Node interface
+
+	return sl.Token.Pos
+}
+
+func (sl *StringLiteral) End() token.Position {
+	// Implements the Node interface
+
+	return sl.Token.EndPosition()
+}
+
+func (sl *StringLiteral) String() string {
+	// Returns the string literal as a string
+
+	return sl.Token.Literal
+}
+
+func (sl *StringLiteral) IsRaw() bool {
+	// Checks if the string was written between backticks
+
+	return sl.Token.Type == token.RAW_STRING
+}
+
+type ImportStatement struct {
+	// Holds an import statement, which binds the top-level lets of another file to a namespace named
+	// after the last element of its path
+	// import "lib/strings"; => holds: IMPORT, the path, and Identifier(IDENT, "strings")
+
+	Token token.Token // The token.IMPORT token
+	Path  *StringLiteral
+	Name  *Identifier // The namespace the module is bound to
+}
+
+// Implements the Statement interface
+func (is *ImportStatement) statementNode() {}
+
+func (is *ImportStatement) TokenLiteral() string {
+	// Implements the Node interface
+
+	return is.Token.Literal
+}
+
+func (is *ImportStatement) Pos() token.Position {
+	// Implements the Node interface
+
+	return is.Token.Pos
+}
+
+func (is *ImportStatement) End() token.Position {
+	// Implements the Node interface
+
+	return is.Path.End()
+}
+
+func (is *ImportStatement) String() string {
+	// Returns `import "<path>";` as a string
+
+	return is.TokenLiteral() + " \"" + is.Path.Value + "\";"
+}
+
+type BlockStatement struct {
+	// Holds the statements between a pair of braces
+	// { x; y; } => holds: {, the statements, and }
+
+	Token      token.Token // The { token
+	Statements []Statement
+	Rbrace     token.Token // The closing } token
+}
+
+// Implements the Statement interface
+func (bs *BlockStatement) statementNode() {}
+
+func (bs *BlockStatement) TokenLiteral() string {
+	// Implements the Node interface
+
+	return bs.Token.Literal
+}
+
+func (bs *BlockStatement) Pos() token.Position {
+	// Implements the Node interface
+
+	return bs.Token.Pos
+}
+
+func (bs *BlockStatement) End() token.Position {
+	// Implements the Node interface
+
+	return bs.Rbrace.EndPosition()
+}
+
+func (bs *BlockStatement) String() string {
+	// Returns the statements of the block one after the other as a string
+
+	var out bytes.Buffer
+
+	for _, s := range bs.Statements {
+		out.WriteString(s.String())
+	}
+
+	return out.String()
+}
+
+type ForStatement struct {
+	// Holds a loop over the elements of an array, or the keys and values of a hash
+	// for (x in arr) { ... } => holds: FOR, Identifier(IDENT, "x"), the iterable, and the body
+	// for (k, v in hash) { ... } => Key holds k and Value holds v
+
+	Token    token.Token // The token.FOR token
+	Key      *Identifier // Only set when two variables are given
+	Value    *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+// Implements the Statement interface
+func (fs *ForStatement) statementNode() {}
+
+func (fs *ForStatement) TokenLiteral() string {
+	// Implements the Node interface
+
+	return fs.Token.Literal
+}
+
+func (fs *ForStatement) Pos() token.Position {
+	// Implements the Node interface
+
+	return fs.Token.Pos
+}
+
+func (fs *ForStatement) End() token.Position {
+	// Implements the Node interface
+
+	return fs.Body.End()
+}
+
+func (fs *ForStatement) String() string {
+	// Returns `for (<key>, <value> in <iterable>) <body>` as a string
+
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+
+	if fs.Key != nil {
+		out.WriteString(fs.Key.String() + ", ")
+	}
+
+	out.WriteString(fs.Value.String() + " in ")
+
+	if fs.Iterable != nil {
+		out.WriteString(fs.Iterable.String())
+	}
+
+	out.WriteString(") " + fs.Body.String())
+
+	return out.String()
+}
+
+type TypeName struct {
+	// Holds the name of a type in an annotation; the evaluator ignores annotations, they're only
+	// there for the type checker and documentation tools
+	// let x: int = 5; => holds: Identifier(IDENT, "int")
+
+	Token token.Token // The token.IDENT token
+	Value string
+}
+
+func (tn *TypeName) TokenLiteral() string {
+	// Implements the Node interface
+
+	return tn.Token.Literal
+}
+
+func (tn *TypeName) Pos() token.Position {
+	// Implements the Node interface
+
+	return tn.Token.Pos
+}
+
+func (tn *TypeName) End() token.Position {
+	// Implements the Node interface
+
+	return tn.Token.EndPosition()
+}
+
+func (tn *TypeName) String() string {
+	// Returns the name of the type as a string
+
+	return tn.Value
+}
+
+type InterpolatedString struct {
+	// Holds a string with embedded expressions as a sequence of parts alternating between string
+	// literals, which hold the text, and the expressions; the first and last parts are always
+	// literals, which may be empty, so parts at even indices are text and those at odd indices are
+	// expressions, which can be string literals themselves
+	// "sum: ${a + b}!" => holds: STRING_HEAD, StringLiteral("sum: "), (a + b), and StringLiteral("!")
+
+	Token token.Token // The token.STRING_HEAD token
+	Parts []Expression
+}
+
+// Implements the Expression interface
+func (is *InterpolatedString) expressionNode() {}
+
+func (is *InterpolatedString) TokenLiteral() string {
+	// Implements the Node interface
+
+	return is.Token.Literal
+}
+
+func (is *InterpolatedString) Pos() token.Position {
+	// Implements the Node interface
+
+	return is.Token.Pos
+}
+
+func (is *InterpolatedString) End() token.Position {
+	// Implements the Node interface
+
+	if len(is.Parts) > 0 {
+		return is.Parts[len(is.Parts)-1].End()
+	}
+
+	return is.Token.EndPosition()
+}
+
+func (is *InterpolatedString) String() string {
+	// Returns the text of the string with each embedded expression written as `${<expression>}`
+
+	var out bytes.Buffer
+
+	for i, part := range is.Parts {
+		if i%2 == 0 {
+			out.WriteString(part.(*StringLiteral).Value)
+		} else if part != nil {
+			out.WriteString("${" + part.String() + "}")
+		}
+	}
+
+	return out.String()
+}
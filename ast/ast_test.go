@@ -4,6 +4,8 @@ package ast
 
 import (
 	"monkey/token"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -30,3 +32,228 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() wrong. got=%q", program.String())
 	}
 }
+
+func TestDump(t *testing.T) {
+	// Compares the typed tree dump of a hand-built program with the expected output
+
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name: &Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "x"},
+					Value: "x",
+				},
+				Value: &InfixExpression{
+					Token:    token.Token{Type: token.PLUS, Literal: "+"},
+					Left:     &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+					Operator: "+",
+					Right: &PrefixExpression{
+						Token:    token.Token{Type: token.MINUS, Literal: "-"},
+						Operator: "-",
+						Right:    &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "2"}, Value: 2},
+					},
+				},
+			},
+			&ReturnStatement{
+				Token: token.Token{Type: token.RETURN, Literal: "return"},
+			},
+		},
+	}
+
+	expected := `(Program
+  (LetStatement
+    (Identifier x)
+    (InfixExpression +
+      (IntegerLiteral 1)
+      (PrefixExpression -
+        (IntegerLiteral 2))))
+  (ReturnStatement
+    nil))`
+
+	if actual := Dump(program); actual != expected {
+		t.Errorf("Dump(program) wrong.\nexpected=%s\ngot=%s", expected, actual)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	// Checks that every node is visited in source order and that returning false prunes a subtree
+
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Name: &Identifier{Value: "x"},
+				Value: &InfixExpression{
+					Left:     &Identifier{Value: "a"},
+					Operator: "+",
+					Right:    &PrefixExpression{Operator: "-", Right: &Identifier{Value: "b"}},
+				},
+			},
+			&ReturnStatement{ReturnValue: &Identifier{Value: "x"}},
+		},
+	}
+
+	var visited []string
+
+	Inspect(program, func(n Node) bool {
+		if ident, ok := n.(*Identifier); ok {
+			visited = append(visited, ident.Value)
+		}
+
+		// Skip the operands of prefix expressions
+		_, isPrefix := n.(*PrefixExpression)
+		return !isPrefix
+	})
+
+	if strings.Join(visited, ",") != "x,a,x" {
+		t.Errorf("wrong identifiers visited. got=%q", visited)
+	}
+}
+
+func TestToDot(t *testing.T) {
+	// Compares the Graphviz graph of a hand-built program with the expected output
+
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name: &Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "s"},
+					Value: "s",
+				},
+				Value: &InfixExpression{
+					Token:    token.Token{Type: token.PLUS, Literal: "+"},
+					Left:     &StringLiteral{Token: token.Token{Type: token.STRING, Literal: "a"}, Value: "a"},
+					Operator: "+",
+					Right:    &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+				},
+			},
+		},
+	}
+
+	expected := `digraph AST {
+	node [shape=box, fontname=monospace];
+	n0 [label="Program"];
+	n1 [label="LetStatement"];
+	n2 [label="Identifier s"];
+	n1 -> n2;
+	n3 [label="InfixExpression +"];
+	n4 [label="StringLiteral \"a\""];
+	n3 -> n4;
+	n5 [label="IntegerLiteral 1"];
+	n3 -> n5;
+	n1 -> n3;
+	n0 -> n1;
+}
+`
+
+	if actual := ToDot(program); actual != expected {
+		t.Errorf("ToDot wrong.\nexpected=%s\ngot=%s", expected, actual)
+	}
+}
+
+func TestToDotMatch(t *testing.T) {
+	// Match arms get boxes of their own so patterns, values, and the default can be told apart
+
+	ident := func(name string) *Identifier {
+		return &Identifier{Token: token.Token{Type: token.IDENT, Literal: name}, Value: name}
+	}
+	integer := func(value int64) *IntegerLiteral {
+		literal := strconv.FormatInt(value, 10)
+		return &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: literal}, Value: value}
+	}
+
+	match := &MatchExpression{
+		Token:   token.Token{Type: token.MATCH, Literal: "match"},
+		Subject: ident("x"),
+		Arms:    []*MatchArm{{Pattern: integer(1), Value: integer(2)}},
+		Default: integer(3),
+	}
+
+	expected := `digraph AST {
+	node [shape=box, fontname=monospace];
+	n0 [label="MatchExpression"];
+	n1 [label="Identifier x"];
+	n0 -> n1 [label="subject"];
+	n2 [label="MatchArm"];
+	n3 [label="IntegerLiteral 1"];
+	n2 -> n3 [label="pattern"];
+	n4 [label="IntegerLiteral 2"];
+	n2 -> n4 [label="value"];
+	n0 -> n2;
+	n5 [label="DefaultArm"];
+	n6 [label="IntegerLiteral 3"];
+	n5 -> n6 [label="value"];
+	n0 -> n5;
+}
+`
+
+	if actual := ToDot(match); actual != expected {
+		t.Errorf("ToDot wrong.\nexpected=%s\ngot=%s", expected, actual)
+	}
+}
+
+func TestFprint(t *testing.T) {
+	// Compares the labelled output of Fprint with and without tokens, positions, and a custom indent
+
+	x := &Identifier{
+		Token: token.Token{
+			Type:    token.IDENT,
+			Literal: "x",
+			Pos:     token.Position{Offset: 4, Line: 1, Column: 5},
+			Length:  1,
+		},
+		Value: "x",
+	}
+
+	program := &Program{
+		Statements: []Statement{
+			&LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name:  x,
+				Value: &PrefixExpression{
+					Token:    token.Token{Type: token.MINUS, Literal: "-"},
+					Operator: "-",
+					Right:    &IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+				},
+			},
+		},
+	}
+
+	expected := `Program {
+  Statements: [
+    LetStatement {
+      Name: Identifier {
+        Value: "x"
+      }
+      Type: nil
+      Value: PrefixExpression {
+        Operator: "-"
+        Right: IntegerLiteral {
+          Value: 1
+        }
+      }
+    }
+  ]
+  Comments: nil
+}
+`
+
+	var out strings.Builder
+	if err := Fprint(&out, program, PrintOptions{}); err != nil {
+		t.Fatalf("Fprint returned error: %s", err)
+	}
+
+	if out.String() != expected {
+		t.Errorf("Fprint(program) wrong.\nexpected=%s\ngot=%s", expected, out.String())
+	}
+
+	expected = "Identifier 1:5-1:6 {\n\tToken: IDENT \"x\" 1:5\n\tValue: \"x\"\n}\n"
+
+	out.Reset()
+	Fprint(&out, x, PrintOptions{Indent: "\t", Tokens: true, Positions: true})
+
+	if out.String() != expected {
+		t.Errorf("Fprint(x) wrong.\nexpected=%q\ngot=%q", expected, out.String())
+	}
+}
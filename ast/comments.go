@@ -0,0 +1,35 @@
+// ast/comments.go
+
+package ast
+
+import "monkey/token"
+
+type Comment struct {
+	// Holds a single `//` comment; only produced when the parser runs with comments enabled
+
+	Token token.Token // The token.COMMENT token
+	Text  string      // The comment including the leading `//`
+}
+
+func (c *Comment) Pos() token.Position {
+	// Returns where the comment starts
+
+	return c.Token.Pos
+}
+
+func (c *Comment) End() token.Position {
+	// Returns where the comment ends, before the line break after it
+
+	return c.Token.EndPosition()
+}
+
+type NodeComments struct {
+	// The comments attached to a node: those on the lines before it, and those after it on the line
+	// where it ends
+
+	Leading  []*Comment
+	Trailing []*Comment
+}
+
+// Maps statements to their comments; comments after the last statement are attached to the Program
+type CommentMap map[Node]*NodeComments
@@ -0,0 +1,121 @@
+// ast/dot.go
+
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func ToDot(node Node) string {
+	// Returns a Graphviz graph of the tree rooted at `node`, with one box per node labelled like in
+	// Dump and an edge from each node to each of its children, e.g. `dot -Tsvg` renders it
+
+	var out bytes.Buffer
+
+	out.WriteString("digraph AST {\n")
+	out.WriteString("\tnode [shape=box, fontname=monospace];\n")
+
+	id := 0
+
+	// Adds a box with the given label and returns its id
+	box := func(text string) int {
+		self := id
+		id++
+
+		fmt.Fprintf(&out, "\tn%d [label=%s];\n", self, strconv.Quote(text))
+
+		return self
+	}
+
+	// Adds an edge, labelled with the role of the child if it isn't obvious from the parent
+	edge := func(from, to int, role string) {
+		if role == "" {
+			fmt.Fprintf(&out, "\tn%d -> n%d;\n", from, to)
+			return
+		}
+
+		fmt.Fprintf(&out, "\tn%d -> n%d [label=%s];\n", from, to, strconv.Quote(role))
+	}
+
+	var visit func(node Node) int
+
+	// Adds an edge to the tree rooted at `node`, skipping the nil nodes left by parse errors
+	child := func(from int, node Node, role string) {
+		if !isNil(node) {
+			edge(from, visit(node), role)
+		}
+	}
+
+	visit = func(node Node) int {
+		self := box(label(node))
+
+		// Match arms aren't nodes, so like in Dump they get boxes of their own to keep each pattern
+		// together with its value and apart from the default
+		if node, ok := node.(*MatchExpression); ok {
+			child(self, node.Subject, "subject")
+
+			for _, arm := range node.Arms {
+				armID := box("MatchArm")
+				child(armID, arm.Pattern, "pattern")
+				child(armID, arm.Value, "value")
+				edge(self, armID, "")
+			}
+
+			if !isNil(node.Default) {
+				defaultID := box("DefaultArm")
+				child(defaultID, node.Default, "value")
+				edge(self, defaultID, "")
+			}
+
+			return self
+		}
+
+		for _, c := range Children(node) {
+			child(self, c, "")
+		}
+
+		return self
+	}
+
+	if !isNil(node) {
+		visit(node)
+	}
+
+	out.WriteString("}\n")
+
+	return out.String()
+}
+
+func label(node Node) string {
+	// Returns the type name of a node followed by the value it holds, if any, e.g. `Identifier x`
+
+	name := strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast.")
+
+	switch node := node.(type) {
+	case *LetStatement:
+		if node.IsConst() {
+			return name + " const"
+		}
+	case *Identifier:
+		return name + " " + node.Value
+	case *TypeName:
+		return name + " " + node.Value
+	case *IntegerLiteral:
+		return name + " " + node.Token.Literal
+	case *CharLiteral:
+		return name + " " + strconv.QuoteRune(node.Value)
+	case *StringLiteral:
+		return name + " " + strconv.Quote(node.Value)
+	case *PrefixExpression:
+		return name + " " + node.Operator
+	case *InfixExpression:
+		return name + " " + node.Operator
+	case *ImportStatement:
+		return name + " " + node.Name.Value
+	}
+
+	return name
+}
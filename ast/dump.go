@@ -0,0 +1,189 @@
+// ast/dump.go
+
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Number of spaces each level of the tree is indented by in Dump's output
+const DUMP_INDENT = 2
+
+func Dump(node Node) string {
+	// Returns an indented S-expression of the tree rooted at `node` where every node is labelled with
+	// its type, e.g. `let x = 1 + 2;` becomes
+	//
+	//   (Program
+	//     (LetStatement
+	//       (Identifier x)
+	//       (InfixExpression +
+	//         (IntegerLiteral 1)
+	//         (IntegerLiteral 2))))
+	//
+	// Unlike String(), this makes node types and grouping explicit, which helps with debugging
+	// precedence issues
+
+	d := &dumper{}
+	d.node(node)
+
+	return d.out.String()
+}
+
+type dumper struct {
+	// Accumulates the output of Dump and tracks the current nesting depth
+
+	out   bytes.Buffer
+	depth int
+}
+
+func (d *dumper) node(node Node) {
+	// Writes a single node and all of its children
+
+	if isNil(node) {
+		d.out.WriteString("nil")
+		return
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		d.open("Program")
+		for _, s := range node.Statements {
+			d.child(s)
+		}
+	case *LetStatement:
+		if node.IsConst() {
+			d.open("LetStatement", "const")
+		} else {
+			d.open("LetStatement")
+		}
+		d.child(node.Name)
+		if node.Type != nil {
+			d.child(node.Type)
+		}
+		d.child(node.Value)
+	case *ReturnStatement:
+		d.open("ReturnStatement")
+		d.child(node.ReturnValue)
+	case *ExpressionStatement:
+		d.open("ExpressionStatement")
+		d.child(node.Expression)
+	case *ImportStatement:
+		d.open("ImportStatement", node.Name.Value)
+		d.child(node.Path)
+	case *BlockStatement:
+		d.open("BlockStatement")
+		for _, s := range node.Statements {
+			d.child(s)
+		}
+	case *ForStatement:
+		d.open("ForStatement")
+		// The key is left out entirely when looping over values only
+		if node.Key != nil {
+			d.child(node.Key)
+		}
+		d.child(node.Value)
+		d.child(node.Iterable)
+		d.child(node.Body)
+	case *Identifier:
+		d.open("Identifier", node.Value)
+	case *TypeName:
+		d.open("TypeName", node.Value)
+	case *IntegerLiteral:
+		d.open("IntegerLiteral", node.Token.Literal)
+	case *CharLiteral:
+		d.open("CharLiteral", strconv.QuoteRune(node.Value))
+	case *StringLiteral:
+		d.open("StringLiteral", strconv.Quote(node.Value))
+	case *InterpolatedString:
+		d.open("InterpolatedString")
+		for _, part := range node.Parts {
+			d.child(part)
+		}
+	case *PrefixExpression:
+		d.open("PrefixExpression", node.Operator)
+		d.child(node.Right)
+	case *InfixExpression:
+		d.open("InfixExpression", node.Operator)
+		d.child(node.Left)
+		d.child(node.Right)
+	case *IndexExpression:
+		d.open("IndexExpression")
+		d.child(node.Left)
+		d.child(node.Index)
+	case *SliceExpression:
+		d.open("SliceExpression")
+		d.child(node.Left)
+		d.child(node.Low)
+		d.child(node.High)
+	case *MatchExpression:
+		d.open("MatchExpression")
+		d.child(node.Subject)
+		for _, arm := range node.Arms {
+			d.newline()
+			d.open("MatchArm")
+			d.child(arm.Pattern)
+			d.child(arm.Value)
+			d.close()
+		}
+		if node.Default != nil {
+			d.newline()
+			d.open("DefaultArm")
+			d.child(node.Default)
+			d.close()
+		}
+	default:
+		// Fall back to the type name and String() for nodes Dump doesn't know about yet
+		d.open(strings.TrimPrefix(fmt.Sprintf("%T", node), "*ast."), node.String())
+	}
+
+	d.close()
+}
+
+func (d *dumper) open(name string, atoms ...string) {
+	// Starts a node with its type name followed by any plain values on the same line
+
+	d.out.WriteString("(" + name)
+
+	for _, atom := range atoms {
+		d.out.WriteString(" " + atom)
+	}
+
+	d.depth++
+}
+
+func (d *dumper) child(node Node) {
+	// Writes a child node on its own line, indented one level deeper than its parent
+
+	d.newline()
+	d.node(node)
+}
+
+func (d *dumper) newline() {
+	// Starts a new line at the current depth
+
+	d.out.WriteString("\n" + strings.Repeat(" ", d.depth*DUMP_INDENT))
+}
+
+func (d *dumper) close() {
+	// Ends the current node
+
+	d.depth--
+	d.out.WriteString(")")
+}
+
+func isNil(node Node) bool {
+	// Checks for both a nil interface and an interface holding a nil pointer, since parse functions
+	// that fail return the latter
+
+	if node == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(node)
+
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
@@ -0,0 +1,161 @@
+// ast/print.go
+
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"monkey/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type PrintOptions struct {
+	// Controls the output of Fprint; the zero value indents by DUMP_INDENT spaces and leaves out
+	// tokens and positions
+
+	Indent    string // Written once for each level of nesting; DUMP_INDENT spaces if empty
+	Tokens    bool   // Also print the Token fields, which mostly repeat the fields next to them
+	Positions bool   // Print the span of each node, from Pos() to End(), after its type
+}
+
+func Fprint(w io.Writer, node Node, opts PrintOptions) error {
+	// Writes the tree rooted at `node` with every field labelled and every node on its own lines,
+	// e.g. `let x = -1;` becomes
+	//
+	//   Program {
+	//     Statements: [
+	//       LetStatement {
+	//         Name: Identifier {
+	//           Value: "x"
+	//         }
+	//         Type: nil
+	//         Value: PrefixExpression {
+	//           Operator: "-"
+	//           Right: IntegerLiteral {
+	//             Value: 1
+	//           }
+	//         }
+	//       }
+	//     ]
+	//     Comments: nil
+	//   }
+	//
+	// This is longer than Dump's output but shows what each child is to its parent
+
+	if opts.Indent == "" {
+		opts.Indent = strings.Repeat(" ", DUMP_INDENT)
+	}
+
+	pr := &printer{opts: opts}
+	pr.value(reflect.ValueOf(node))
+	pr.out.WriteString("\n")
+
+	_, err := w.Write(pr.out.Bytes())
+
+	return err
+}
+
+type printer struct {
+	// Accumulates the output of Fprint and tracks the current nesting depth
+
+	opts  PrintOptions
+	out   bytes.Buffer
+	depth int
+}
+
+var tokenType = reflect.TypeOf(token.Token{})
+
+func (pr *printer) value(v reflect.Value) {
+	// Writes a single value, continuing on the following lines if it has fields or elements
+
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	if !v.IsValid() || (v.Kind() == reflect.Ptr || v.Kind() == reflect.Map) && v.IsNil() {
+		pr.out.WriteString("nil")
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		pr.structure(v)
+	case reflect.Slice:
+		if v.Len() == 0 {
+			pr.out.WriteString("[]")
+			return
+		}
+
+		pr.out.WriteString("[")
+		pr.depth++
+		for i := 0; i < v.Len(); i++ {
+			pr.newline()
+			pr.value(v.Index(i))
+		}
+		pr.depth--
+		pr.newline()
+		pr.out.WriteString("]")
+	case reflect.Map:
+		// Comment maps are keyed by nodes that are printed elsewhere, so only their size is shown
+		fmt.Fprintf(&pr.out, "%s (len = %d)", v.Type().Name(), v.Len())
+	case reflect.String:
+		pr.out.WriteString(strconv.Quote(v.String()))
+	case reflect.Struct:
+		if v.Type() == tokenType {
+			tok := v.Interface().(token.Token)
+			fmt.Fprintf(&pr.out, "%s %q %s", tok.Type, tok.Literal, tok.Pos)
+			return
+		}
+		pr.structure(v)
+	default:
+		fmt.Fprint(&pr.out, v.Interface())
+	}
+}
+
+func (pr *printer) structure(v reflect.Value) {
+	// Writes a struct, or a pointer to one, as its type name followed by its labelled fields
+
+	name := v.Type().Name()
+
+	if v.Kind() == reflect.Ptr {
+		name = v.Elem().Type().Name()
+		if node, ok := v.Interface().(Node); ok && pr.opts.Positions {
+			name += fmt.Sprintf(" %s-%s", node.Pos(), node.End())
+		}
+		v = v.Elem()
+	}
+
+	pr.out.WriteString(name + " {")
+	pr.fields(v)
+}
+
+func (pr *printer) fields(v reflect.Value) {
+	// Writes the fields of a struct one per line and closes it
+
+	pr.depth++
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		if !field.IsExported() || field.Type == tokenType && !pr.opts.Tokens {
+			continue
+		}
+
+		pr.newline()
+		pr.out.WriteString(field.Name + ": ")
+		pr.value(v.Field(i))
+	}
+
+	pr.depth--
+	pr.newline()
+	pr.out.WriteString("}")
+}
+
+func (pr *printer) newline() {
+	// Starts a new line at the current depth
+
+	pr.out.WriteString("\n" + strings.Repeat(pr.opts.Indent, pr.depth))
+}
@@ -0,0 +1,79 @@
+// ast/walk.go
+
+package ast
+
+func Inspect(node Node, f func(Node) bool) {
+	// Traverses the tree rooted at `node` depth-first in source order, calling `f` on every non-nil
+	// node; the children of a node are skipped if `f` returns false
+
+	if isNil(node) || !f(node) {
+		return
+	}
+
+	for _, child := range Children(node) {
+		Inspect(child, f)
+	}
+}
+
+func Children(node Node) []Node {
+	// Returns the direct, non-nil children of a node in source order
+
+	var nodes []Node
+
+	add := func(n Node) {
+		if !isNil(n) {
+			nodes = append(nodes, n)
+		}
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		for _, s := range node.Statements {
+			add(s)
+		}
+	case *LetStatement:
+		add(node.Name)
+		add(node.Type)
+		add(node.Value)
+	case *ReturnStatement:
+		add(node.ReturnValue)
+	case *ExpressionStatement:
+		add(node.Expression)
+	case *ImportStatement:
+		add(node.Path)
+	case *BlockStatement:
+		for _, s := range node.Statements {
+			add(s)
+		}
+	case *ForStatement:
+		add(node.Key)
+		add(node.Value)
+		add(node.Iterable)
+		add(node.Body)
+	case *InterpolatedString:
+		for _, part := range node.Parts {
+			add(part)
+		}
+	case *PrefixExpression:
+		add(node.Right)
+	case *InfixExpression:
+		add(node.Left)
+		add(node.Right)
+	case *IndexExpression:
+		add(node.Left)
+		add(node.Index)
+	case *SliceExpression:
+		add(node.Left)
+		add(node.Low)
+		add(node.High)
+	case *MatchExpression:
+		add(node.Subject)
+		for _, arm := range node.Arms {
+			add(arm.Pattern)
+			add(arm.Value)
+		}
+		add(node.Default)
+	}
+
+	return nodes
+}
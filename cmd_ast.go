@@ -0,0 +1,87 @@
+// cmd_ast.go
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"os"
+)
+
+func runAst(args []string) int {
+	// Implements `monkey ast [-dot | -fields] [files]`: prints the parse tree of each file, or of
+	// stdin if no files are given, as an S-expression, a Graphviz graph with -dot, or with every
+	// field labelled with -fields
+
+	flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+	dot := flags.Bool("dot", false, "print a Graphviz graph instead of an S-expression")
+	fields := flags.Bool("fields", false, "print every field of every node with its name and span")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *dot && *fields {
+		fmt.Fprintln(os.Stderr, "monkey ast: -dot and -fields can't be used together")
+		return 2
+	}
+
+	show := func(filename string, src string) int {
+		return printAst(filename, src, *dot, *fields)
+	}
+
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "monkey ast: %s\n", err)
+			return 1
+		}
+
+		return show("<stdin>", string(src))
+	}
+
+	status := 0
+
+	for _, filename := range flags.Args() {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "monkey ast: %s\n", err)
+			status = 1
+			continue
+		}
+
+		if show(filename, string(src)) != 0 {
+			status = 1
+		}
+	}
+
+	return status
+}
+
+func printAst(filename string, src string, dot bool, fields bool) int {
+	// Parses a single source file and prints its tree
+
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			printErrors(os.Stderr, filename, src, err)
+		}
+		return 1
+	}
+
+	if dot {
+		fmt.Print(ast.ToDot(program))
+	} else if fields {
+		ast.Fprint(os.Stdout, program, ast.PrintOptions{Positions: true})
+	} else {
+		fmt.Println(ast.Dump(program))
+	}
+
+	return 0
+}
@@ -0,0 +1,70 @@
+// cmd_check.go
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"monkey/lexer"
+	"monkey/parser"
+	"monkey/types"
+	"os"
+)
+
+func runCheck(args []string) int {
+	// Implements `monkey check [files]`: reports probable type errors in each file, or in stdin if
+	// no files are given; exits with 1 if anything was reported
+
+	if len(args) == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "monkey check: %s\n", err)
+			return 1
+		}
+
+		return checkFile("<stdin>", string(src))
+	}
+
+	status := 0
+
+	for _, filename := range args {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "monkey check: %s\n", err)
+			status = 1
+			continue
+		}
+
+		if checkFile(filename, string(src)) != 0 {
+			status = 1
+		}
+	}
+
+	return status
+}
+
+func checkFile(filename string, src string) int {
+	// Type checks a single source file
+
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			printErrors(os.Stderr, filename, src, err)
+		}
+		return 1
+	}
+
+	errors := types.Check(program)
+
+	for _, err := range errors {
+		fmt.Printf("%s:%s\n", filename, err.Error())
+	}
+
+	if len(errors) != 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,61 @@
+// cmd_deps.go
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"monkey/module"
+	"os"
+)
+
+func runDeps(args []string) int {
+	// Implements `monkey deps [-path dirs] modules`: loads each module by import path and prints the
+	// files it depends on, imports before the modules that import them
+
+	flags := flag.NewFlagSet("deps", flag.ContinueOnError)
+	dirs := flags.String("path", "",
+		"module roots searched before $"+module.PATH_ENV+", separated like PATH")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	loader := module.NewLoader(module.SearchPath(*dirs)...)
+	printed := map[*module.Module]bool{}
+	status := 0
+
+	for _, importPath := range flags.Args() {
+		m, err := loader.Load(importPath)
+		if err != nil {
+			var parseErr *module.ParseError
+			if errors.As(err, &parseErr) {
+				printErrors(os.Stderr, parseErr.File, parseErr.Source, parseErr.Err)
+			} else {
+				fmt.Fprintf(os.Stderr, "monkey deps: %s\n", err)
+			}
+			status = 1
+			continue
+		}
+
+		printDeps(m, printed)
+	}
+
+	return status
+}
+
+func printDeps(m *module.Module, printed map[*module.Module]bool) {
+	// Prints the files of a module's imports, then its own, skipping anything already printed
+
+	if printed[m] {
+		return
+	}
+	printed[m] = true
+
+	for _, imported := range m.Imports {
+		printDeps(imported, printed)
+	}
+
+	fmt.Printf("%s\t%s\n", m.Path, m.File)
+}
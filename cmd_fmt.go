@@ -0,0 +1,76 @@
+// cmd_fmt.go
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"monkey/format"
+	"os"
+)
+
+func runFmt(args []string) int {
+	// Implements `monkey fmt [-w] [files]`: prints each file in canonical form, or rewrites the files
+	// in place with -w; reads from stdin if no files are given
+
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	write := flags.Bool("w", false, "write the result back to the source file instead of stdout")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "monkey fmt: %s\n", err)
+			return 1
+		}
+
+		return formatFile("<stdin>", string(src), false)
+	}
+
+	status := 0
+
+	for _, filename := range flags.Args() {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "monkey fmt: %s\n", err)
+			status = 1
+			continue
+		}
+
+		if formatFile(filename, string(src), *write) != 0 {
+			status = 1
+		}
+	}
+
+	return status
+}
+
+func formatFile(filename string, src string, write bool) int {
+	// Formats a single source file and either prints it or writes it back
+
+	formatted, err := format.Source(src)
+	if err != nil {
+		printErrors(os.Stderr, filename, src, err)
+		return 1
+	}
+
+	if !write {
+		fmt.Print(formatted)
+		return 0
+	}
+
+	if formatted == src {
+		return 0
+	}
+
+	if err := os.WriteFile(filename, []byte(formatted), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "monkey fmt: %s\n", err)
+		return 1
+	}
+
+	return 0
+}
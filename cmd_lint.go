@@ -0,0 +1,105 @@
+// cmd_lint.go
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"monkey/lexer"
+	"monkey/lint"
+	"monkey/parser"
+	"os"
+	"slices"
+	"strings"
+)
+
+func runLint(args []string) int {
+	// Implements `monkey lint [-module] [-disable checks] [files]`: reports probable mistakes in each
+	// file, or in stdin if no files are given; exits with 1 if anything was reported
+
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	disable := flags.String("disable", "",
+		"comma-separated checks to skip, out of "+strings.Join(lint.Checks(), ", "))
+	module := flags.Bool("module", false,
+		"treat files as modules, whose top-level bindings are exports and never unused")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	checks := lint.Checks()
+
+	if *disable != "" {
+		for _, name := range strings.Split(*disable, ",") {
+			name = strings.TrimSpace(name)
+
+			if !slices.Contains(lint.Checks(), name) {
+				fmt.Fprintf(os.Stderr, "monkey lint: unknown check %q\n", name)
+				return 2
+			}
+
+			checks = slices.DeleteFunc(checks, func(c string) bool { return c == name })
+		}
+	}
+
+	args = flags.Args()
+
+	if len(args) == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "monkey lint: %s\n", err)
+			return 1
+		}
+
+		return lintFile("<stdin>", string(src), checks, *module)
+	}
+
+	status := 0
+
+	for _, filename := range args {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "monkey lint: %s\n", err)
+			status = 1
+			continue
+		}
+
+		if lintFile(filename, string(src), checks, *module) != 0 {
+			status = 1
+		}
+	}
+
+	return status
+}
+
+func lintFile(filename string, src string, checks []string, module bool) int {
+	// Lints a single source file with the given checks, as a module if `module` is set
+
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			printErrors(os.Stderr, filename, src, err)
+		}
+		return 1
+	}
+
+	var issues []lint.Issue
+	if module {
+		issues = lint.CheckModule(program, checks...)
+	} else {
+		issues = lint.CheckOnly(program, checks...)
+	}
+
+	for _, issue := range issues {
+		fmt.Printf("%s:%s\n", filename, issue.Error())
+	}
+
+	if len(issues) != 0 {
+		return 1
+	}
+
+	return 0
+}
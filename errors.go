@@ -0,0 +1,30 @@
+// errors.go
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"monkey/parser"
+)
+
+func printErrors(out io.Writer, filename string, src string, err error) {
+	// Prints an error prefixed with the file name; parser errors, including several joined together,
+	// are followed by an excerpt of the offending source line
+
+	var errs []error
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	} else {
+		errs = []error{err}
+	}
+
+	for _, e := range errs {
+		if pe, ok := e.(parser.ParserError); ok {
+			fmt.Fprintf(out, "%s:%s", filename, pe.Format(src))
+		} else {
+			fmt.Fprintf(out, "%s: %s\n", filename, e)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+// format/format.go
+
+package format
+
+import (
+	"bytes"
+	"errors"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"monkey/token"
+	"strings"
+	"unicode"
+)
+
+// String used for each level of indentation
+const INDENT = "\t"
+
+func Source(src string) (string, error) {
+	// Parses monkey source and reprints it in canonical form; if the source doesn't parse, returns
+	// the parser errors joined into a single error and leaves the source alone
+
+	p := parser.NewWithMode(lexer.New(src), parser.ParseComments)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		errs := make([]error, len(p.Errors()))
+		for i, err := range p.Errors() {
+			errs[i] = err
+		}
+		return "", errors.Join(errs...)
+	}
+
+	return node(program, p.Precedence), nil
+}
+
+func Node(n ast.Node) string {
+	// Returns the canonical source for a node: one statement per line terminated by a semicolon,
+	// single spaces around infix operators, and parentheses only where precedence requires them;
+	// operators have the precedences a parser created now would give them
+
+	return node(n, parser.OperatorPrecedence)
+}
+
+func node(n ast.Node, precedence func(token.TokenType) int) string {
+	// Returns the canonical source for a node, with operators grouped by `precedence`
+
+	pr := &printer{precedence: precedence}
+	pr.node(n)
+
+	return pr.out.String()
+}
+
+type printer struct {
+	// Accumulates formatted output and tracks the current indentation level
+
+	out    bytes.Buffer
+	indent int
+
+	// Looks up the precedence of infix operators, the same way the parser of the source does
+	precedence func(token.TokenType) int
+
+	// Comments to print alongside statements, and the last line of the source printed so far, used
+	// to preserve blank lines
+	comments ast.CommentMap
+	lastLine int
+}
+
+func (pr *printer) node(node ast.Node) {
+	// Writes any kind of node
+
+	switch node := node.(type) {
+	case *ast.Program:
+		pr.comments = node.Comments
+		pr.statements(node.Statements)
+		if c := pr.comments[node]; c != nil {
+			pr.commentLines(c.Trailing)
+		}
+	case ast.Statement:
+		pr.statement(node)
+	case ast.Expression:
+		pr.expression(node, parser.LOWEST)
+	}
+}
+
+func (pr *printer) statements(statements []ast.Statement) {
+	// Writes each statement on its own line along with its comments, keeping a single blank line
+	// wherever the source had one or more
+
+	pr.lastLine = 0
+
+	for _, s := range statements {
+		c := pr.comments[s]
+		if c == nil {
+			c = &ast.NodeComments{}
+		}
+
+		pr.commentLines(c.Leading)
+
+		pr.blankLineBefore(s.Pos().Line)
+
+		// Only a comment on the last line of the statement can stay after it; the others are inside a
+		// statement that may be printed on fewer lines than the source, so they go on their own lines
+		// before it rather than running together
+		var trailing *ast.Comment
+		for _, comment := range c.Trailing {
+			if comment.Token.Pos.Line == s.End().Line {
+				trailing = comment
+				continue
+			}
+			pr.out.WriteString(strings.Repeat(INDENT, pr.indent) + comment.Text + "\n")
+		}
+
+		pr.out.WriteString(strings.Repeat(INDENT, pr.indent))
+		pr.statement(s)
+		pr.lastLine = s.End().Line
+
+		if trailing != nil {
+			pr.out.WriteString(" " + trailing.Text)
+		}
+
+		pr.out.WriteString("\n")
+	}
+}
+
+func (pr *printer) commentLines(comments []*ast.Comment) {
+	// Writes comments that sit on their own lines
+
+	for _, comment := range comments {
+		pr.blankLineBefore(comment.Token.Pos.Line)
+		pr.out.WriteString(strings.Repeat(INDENT, pr.indent) + comment.Text + "\n")
+		pr.lastLine = comment.Token.Pos.Line
+	}
+}
+
+func (pr *printer) blankLineBefore(line int) {
+	// Writes a blank line if there was at least one between the last thing printed and `line`
+
+	if pr.lastLine > 0 && line > pr.lastLine+1 {
+		pr.out.WriteString("\n")
+	}
+}
+
+func (pr *printer) statement(s ast.Statement) {
+	// Writes a single statement including its terminating semicolon; statements ending in a block
+	// don't get one
+
+	switch s := s.(type) {
+	case *ast.LetStatement:
+		if s.IsConst() {
+			pr.out.WriteString("const ")
+		} else {
+			pr.out.WriteString("let ")
+		}
+		pr.out.WriteString(s.Name.Value)
+		if s.Type != nil {
+			pr.out.WriteString(": " + s.Type.Value)
+		}
+		pr.out.WriteString(" = ")
+		pr.expression(s.Value, parser.LOWEST)
+	case *ast.ReturnStatement:
+		pr.out.WriteString("return")
+		if s.ReturnValue != nil {
+			pr.out.WriteString(" ")
+			pr.expression(s.ReturnValue, parser.LOWEST)
+		}
+	case *ast.ExpressionStatement:
+		pr.expression(s.Expression, parser.LOWEST)
+	case *ast.ImportStatement:
+		pr.out.WriteString("import ")
+		pr.expression(s.Path, parser.LOWEST)
+	case *ast.ForStatement:
+		pr.out.WriteString("for (")
+		if s.Key != nil {
+			pr.out.WriteString(s.Key.Value + ", ")
+		}
+		pr.out.WriteString(s.Value.Value + " in ")
+		pr.expression(s.Iterable, parser.LOWEST)
+		pr.out.WriteString(") ")
+		pr.block(s.Body)
+		return
+	case *ast.BlockStatement:
+		pr.block(s)
+		return
+	default:
+		pr.out.WriteString(strings.TrimSuffix(s.String(), ";"))
+	}
+
+	pr.out.WriteString(";")
+}
+
+func (pr *printer) block(b *ast.BlockStatement) {
+	// Writes a block with its statements indented one level deeper than the braces; empty blocks are
+	// written as `{}`
+
+	c := pr.comments[b]
+
+	if len(b.Statements) == 0 && c == nil {
+		pr.out.WriteString("{}")
+		return
+	}
+
+	pr.out.WriteString("{\n")
+	pr.indent++
+
+	pr.statements(b.Statements)
+	if c != nil {
+		pr.commentLines(c.Trailing)
+	}
+
+	pr.indent--
+	pr.out.WriteString(strings.Repeat(INDENT, pr.indent) + "}")
+}
+
+func (pr *printer) expression(e ast.Expression, precedence int) {
+	// Writes an expression that appears in a context binding with `precedence`; the expression is
+	// parenthesized if it binds more loosely than its context
+
+	switch e := e.(type) {
+	case *ast.Identifier:
+		pr.out.WriteString(e.Value)
+	case *ast.IntegerLiteral:
+		pr.out.WriteString(e.Token.Literal)
+	case *ast.CharLiteral:
+		pr.out.WriteString(e.String())
+	case *ast.StringLiteral:
+		if e.IsRaw() {
+			pr.out.WriteString("`" + e.Value + "`")
+		} else {
+			pr.out.WriteString("\"" + lexer.Escape(e.Value) + "\"")
+		}
+	case *ast.InterpolatedString:
+		pr.out.WriteString("\"")
+		for i, part := range e.Parts {
+			// The text and the embedded expressions alternate, and the expressions can be strings too
+			if i%2 == 0 {
+				pr.out.WriteString(lexer.Escape(part.(*ast.StringLiteral).Value))
+				continue
+			}
+			pr.out.WriteString("${")
+			pr.expression(part, parser.LOWEST)
+			pr.out.WriteString("}")
+		}
+		pr.out.WriteString("\"")
+	case *ast.PrefixExpression:
+		pr.out.WriteString(e.Operator)
+		// Keyword operators like `not` would otherwise run into their operand
+		if last := e.Operator[len(e.Operator)-1]; last == '_' || unicode.IsLetter(rune(last)) {
+			pr.out.WriteString(" ")
+		}
+		pr.expression(e.Right, parser.PREFIX)
+	case *ast.InfixExpression:
+		opPrecedence := pr.precedence(e.Token.Type)
+
+		if opPrecedence < precedence {
+			pr.out.WriteString("(")
+		}
+
+		// Infix operators are left-associative, so the right operand needs parentheses if it has the
+		// same precedence, e.g. `a - (b - c)`; comparisons can't be chained, so the left one does too
+		// if it's a comparison, e.g. `(a == b) == c`
+		leftPrecedence := opPrecedence
+		if opPrecedence == parser.EQUALS || opPrecedence == parser.LESSGREATER {
+			leftPrecedence++
+		}
+
+		pr.expression(e.Left, leftPrecedence)
+		pr.out.WriteString(" " + e.Operator + " ")
+		pr.expression(e.Right, opPrecedence+1)
+
+		if opPrecedence < precedence {
+			pr.out.WriteString(")")
+		}
+	case *ast.IndexExpression:
+		pr.expression(e.Left, parser.INDEX)
+		pr.out.WriteString("[")
+		pr.expression(e.Index, parser.LOWEST)
+		pr.out.WriteString("]")
+	case *ast.SliceExpression:
+		pr.expression(e.Left, parser.INDEX)
+		pr.out.WriteString("[")
+		pr.expression(e.Low, parser.LOWEST)
+		pr.out.WriteString(":")
+		pr.expression(e.High, parser.LOWEST)
+		pr.out.WriteString("]")
+	case *ast.MatchExpression:
+		pr.out.WriteString("match (")
+		pr.expression(e.Subject, parser.LOWEST)
+		pr.out.WriteString(") {")
+		for i, arm := range e.Arms {
+			if i > 0 {
+				pr.out.WriteString(",")
+			}
+			pr.out.WriteString(" ")
+			pr.expression(arm.Pattern, parser.LOWEST)
+			pr.out.WriteString(" => ")
+			pr.expression(arm.Value, parser.LOWEST)
+		}
+		if e.Default != nil {
+			if len(e.Arms) > 0 {
+				pr.out.WriteString(",")
+			}
+			pr.out.WriteString(" _ => ")
+			pr.expression(e.Default, parser.LOWEST)
+		}
+		if len(e.Arms) > 0 || e.Default != nil {
+			pr.out.WriteString(" ")
+		}
+		pr.out.WriteString("}")
+	case nil:
+	default:
+		pr.out.WriteString(e.String())
+	}
+}
@@ -0,0 +1,252 @@
+// format/format_test.go
+
+package format
+
+import (
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"monkey/token"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	// Compares raw monkey input with its canonical formatting
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x=5", "let x = 5;\n"},
+		{"let x :int=5", "let x: int = 5;\n"},
+		{"const  x=5", "const x = 5;\n"},
+		{"  let   x =  1+2*3 ;return x;", "let x = 1 + 2 * 3;\nreturn x;\n"},
+		{"-a*b;!-a", "-a * b;\n!-a;\n"},
+		{"a + b - c; 5 > 4 == 3 < 4", "a + b - c;\n5 > 4 == 3 < 4;\n"},
+		{"let x = 1;\n\n\n\nlet y = 2;\nx", "let x = 1;\n\nlet y = 2;\nx;\n"},
+		{"let x =\n  1 +\n  2;\nlet y = 3;", "let x = 1 + 2;\nlet y = 3;\n"},
+		{"match(x){1=>a,2=>b*c,_=>0,}", "match (x) { 1 => a, 2 => b * c, _ => 0 };\n"},
+		{"match (x) {}", "match (x) {};\n"},
+		{"-a [ 1 ] [ : n+1 ]*b", "-a[1][:n + 1] * b;\n"},
+		{"for(x in xs){x}", "for (x in xs) {\n\tx;\n}\n"},
+		{"for ( k,v in h ) { } ;x", "for (k, v in h) {}\nx;\n"},
+		{`import  "lib/strings"
+let s="a  b"`, "import \"lib/strings\";\nlet s = \"a  b\";\n"},
+		{`let c='\n'  ;'a'+1`, "let c = '\\n';\n'a' + 1;\n"},
+		{"let j=`{\"a\": ${1}\n}`\nlet k=1", "let j = `{\"a\": ${1}\n}`;\nlet k = 1;\n"},
+		{"let j=`a\n\nb`\n\nlet k=1", "let j = `a\n\nb`;\n\nlet k = 1;\n"},
+		{`"sum: ${a+b}, ${ "x${c[0]}" }!"`, "\"sum: ${a + b}, ${\"x${c[0]}\"}!\";\n"},
+		{`"a ${ "b" } c"`, "\"a ${\"b\"} c\";\n"},
+		{"\"x ${ `\"` } y\"", "\"x ${`\"`} y\";\n"},
+		{`"say \"hi\"\n" + "\${x} = ${x}"`, "\"say \\\"hi\\\"\\n\" + \"\\${x} = ${x}\";\n"},
+		{"\"a\nb\tc\"", "\"a\\nb\\tc\";\n"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		actual, err := Source(tt.input)
+		if err != nil {
+			t.Fatalf("Source(%q) returned error: %s", tt.input, err)
+		}
+
+		if actual != tt.expected {
+			t.Errorf("Source(%q) wrong. expected=%q, got=%q", tt.input, tt.expected, actual)
+		}
+
+		// Formatting must be idempotent
+		again, err := Source(actual)
+		if err != nil || again != actual {
+			t.Errorf("formatting %q is not idempotent. got=%q (err=%v)", actual, again, err)
+		}
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	// Source that doesn't parse is reported rather than reformatted
+
+	_, err := Source("let = 5;")
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	if err.Error() != "1:5: expected next token to be IDENT, got = instead" {
+		t.Errorf("wrong error. got=%q", err.Error())
+	}
+}
+
+func TestNodeParentheses(t *testing.T) {
+	// Hand-built trees whose grouping differs from the default precedence keep their parentheses,
+	// and the output parses back into the same tree
+
+	a := &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "a"}, Value: "a"}
+	b := &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "b"}, Value: "b"}
+	c := &ast.Identifier{Token: token.Token{Type: token.IDENT, Literal: "c"}, Value: "c"}
+
+	infix := func(op token.TokenType, left, right ast.Expression) ast.Expression {
+		// Most operator token types are their own literals
+		literal := map[token.TokenType]string{token.EQ: "==", token.NOT_EQ: "!="}[op]
+		if literal == "" {
+			literal = string(op)
+		}
+
+		return &ast.InfixExpression{
+			Token:    token.Token{Type: op, Literal: literal},
+			Left:     left,
+			Operator: literal,
+			Right:    right,
+		}
+	}
+
+	tests := []struct {
+		node     ast.Expression
+		expected string
+	}{
+		{infix(token.ASTERISK, infix(token.PLUS, a, b), c), "(a + b) * c"},
+		{infix(token.MINUS, a, infix(token.MINUS, b, c)), "a - (b - c)"},
+		{infix(token.MINUS, infix(token.MINUS, a, b), c), "a - b - c"},
+		{&ast.PrefixExpression{Operator: "-", Right: infix(token.PLUS, a, b)}, "-(a + b)"},
+		{infix(token.EQ, infix(token.LT, a, b), c), "a < b == c"},
+		{infix(token.EQ, infix(token.NOT_EQ, a, b), c), "(a != b) == c"},
+		{infix(token.LT, a, infix(token.GT, b, c)), "a < (b > c)"},
+	}
+
+	for _, tt := range tests {
+		actual := Node(tt.node)
+		if actual != tt.expected {
+			t.Errorf("Node(%s) wrong. expected=%q, got=%q", tt.node, tt.expected, actual)
+		}
+
+		p := parser.New(lexer.New(actual))
+		program := p.ParseProgram()
+
+		if len(p.Errors()) != 0 || len(program.Statements) != 1 ||
+			program.Statements[0].String() != tt.node.String() {
+			t.Errorf("%q doesn't parse back into %s. got=%s (errors=%v)", actual, tt.node,
+				program, p.Errors())
+		}
+	}
+
+	// Keyword operators are separated from their operands
+	not := &ast.PrefixExpression{Operator: "not", Right: a}
+	if actual := Node(not); actual != "not a" {
+		t.Errorf("Node(%s) wrong. expected=%q, got=%q", not, "not a", actual)
+	}
+}
+
+func TestSourceComments(t *testing.T) {
+	// Comments survive formatting in their original positions
+
+	input := `// header comment
+
+// leading comment
+let x=1;   // trailing comment
+let y =2;
+   // indented comment
+
+
+x+y // sum
+// final comment
+`
+
+	expected := `// header comment
+
+// leading comment
+let x = 1; // trailing comment
+let y = 2;
+// indented comment
+
+x + y; // sum
+// final comment
+`
+
+	actual, err := Source(input)
+	if err != nil {
+		t.Fatalf("Source returned error: %s", err)
+	}
+
+	if actual != expected {
+		t.Errorf("Source wrong.\nexpected=%q\ngot=%q", expected, actual)
+	}
+
+	again, err := Source(actual)
+	if err != nil || again != actual {
+		t.Errorf("formatting is not idempotent. got=%q (err=%v)", again, err)
+	}
+}
+
+func TestSourceInnerComments(t *testing.T) {
+	// Comments inside a statement that is joined onto one line move above it, each on its own line,
+	// so they don't run together into a single comment
+
+	input := `let a = 1;
+
+let x = match (y) {
+	1 => 2, // one
+	// default
+	_ => 3
+}; // three
+`
+
+	expected := `let a = 1;
+
+// one
+// default
+let x = match (y) { 1 => 2, _ => 3 }; // three
+`
+
+	actual, err := Source(input)
+	if err != nil {
+		t.Fatalf("Source returned error: %s", err)
+	}
+
+	if actual != expected {
+		t.Errorf("Source wrong.\nexpected=%q\ngot=%q", expected, actual)
+	}
+
+	again, err := Source(actual)
+	if err != nil || again != actual {
+		t.Errorf("formatting is not idempotent. got=%q (err=%v)", again, err)
+	}
+}
+
+func TestSourceBlocks(t *testing.T) {
+	// Blocks are indented, and comments inside them stay inside them
+
+	input := `// before
+for (x in xs) { // open
+  let y = x;   // trailing
+
+
+  // nested
+  for (k,v in y){}
+  // last
+} // after
+let z = 1;
+`
+
+	expected := `// before
+for (x in xs) {
+	// open
+	let y = x; // trailing
+
+	// nested
+	for (k, v in y) {}
+	// last
+} // after
+let z = 1;
+`
+
+	actual, err := Source(input)
+	if err != nil {
+		t.Fatalf("Source returned error: %s", err)
+	}
+
+	if actual != expected {
+		t.Errorf("Source wrong.\nexpected=%q\ngot=%q", expected, actual)
+	}
+
+	again, err := Source(actual)
+	if err != nil || again != actual {
+		t.Errorf("formatting is not idempotent. got=%q (err=%v)", again, err)
+	}
+}
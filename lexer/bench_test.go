@@ -0,0 +1,53 @@
+// lexer/bench_test.go
+
+package lexer
+
+import (
+	"fmt"
+	"monkey/token"
+	"strings"
+	"testing"
+)
+
+func benchmarkInput(lines int) string {
+	// Builds a program mixing bindings, arithmetic, comparisons, and comments
+
+	var out strings.Builder
+
+	for i := 0; i < lines; i++ {
+		name := benchmarkName(i)
+
+		fmt.Fprintf(&out, "let %s = a * %d + b / c - -d; // line %d\n", name, i, i)
+		fmt.Fprintf(&out, "%s < 10 == !(%s > 100);\n", name, name)
+		fmt.Fprintf(&out, "return %s != 0;\n", name)
+	}
+
+	return out.String()
+}
+
+func benchmarkName(i int) string {
+	// Returns a distinct identifier for each index; identifiers can't contain digits
+
+	name := "value_"
+
+	for {
+		name += string(rune('a' + i%26))
+		i /= 26
+		if i == 0 {
+			return name
+		}
+	}
+}
+
+func BenchmarkLexer(b *testing.B) {
+	// Measures tokenizing a large program
+
+	input := benchmarkInput(1000)
+	b.SetBytes(int64(len(input)))
+
+	for i := 0; i < b.N; i++ {
+		l := New(input)
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		}
+	}
+}
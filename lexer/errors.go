@@ -0,0 +1,63 @@
+// lexer/errors.go
+
+package lexer
+
+import (
+	"fmt"
+	"monkey/token"
+)
+
+// What is wrong with the input at an illegal token
+type ErrorKind int
+
+const (
+	ILLEGAL_CHAR        ErrorKind = iota // A char that can't start any token
+	UNTERMINATED_STRING                  // The input ended inside a string
+	UNTERMINATED_CHAR                    // The line or input ended inside a character literal
+)
+
+type Error struct {
+	// Describes an illegal token produced by the lexer
+
+	Kind    ErrorKind
+	Pos     token.Position // Where the illegal token starts
+	Message string
+}
+
+func (e Error) Error() string {
+	// Returns the error as "line:column: message"
+
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+}
+
+func (l *Lexer) Errors() []Error {
+	// Returns an error for each illegal token produced so far, in input order; unlike Err, these are
+	// problems with the source text rather than with reading it
+
+	return l.errors
+}
+
+func (l *Lexer) ErrorAt(tok token.Token) (Error, bool) {
+	// Returns the error describing an illegal token produced by the lexer, if there is one
+
+	if tok.Type != token.ILLEGAL {
+		return Error{}, false
+	}
+
+	for _, e := range l.errors {
+		if e.Pos.Offset == tok.Pos.Offset {
+			return e, true
+		}
+	}
+
+	return Error{}, false
+}
+
+func (l *Lexer) illegal(kind ErrorKind, pos token.Position, literal string,
+	message string) token.Token {
+	// Records an error and returns the illegal token it describes
+
+	l.errors = append(l.errors, Error{Kind: kind, Pos: pos, Message: message})
+
+	return token.Token{Type: token.ILLEGAL, Literal: literal}
+}
@@ -0,0 +1,76 @@
+// lexer/escape.go
+
+package lexer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Maps the char after a backslash in a double-quoted string to the char it stands for
+var escapes = map[byte]byte{
+	'\\': '\\',
+	'"':  '"',
+	'$':  '$', // So `\${` is text rather than the start of an embedded expression
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+}
+
+func Unescape(s string) (string, error) {
+	// Interprets the escape sequences in the literal of a STRING, STRING_HEAD, STRING_MIDDLE, or
+	// STRING_TAIL token, which the lexer leaves as written; raw strings have no escapes, so their
+	// literals are used as they are
+
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+
+	var out strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			out.WriteByte(s[i])
+			continue
+		}
+
+		if i+1 == len(s) {
+			return "", fmt.Errorf("string ends with an unfinished escape sequence")
+		}
+
+		i++
+		ch, ok := escapes[s[i]]
+		if !ok {
+			return "", fmt.Errorf("unknown escape sequence \\%c in string", s[i])
+		}
+		out.WriteByte(ch)
+	}
+
+	return out.String(), nil
+}
+
+func Escape(s string) string {
+	// Returns the text to put between double quotes for a string holding `s`, the inverse of
+	// Unescape; `$` is only escaped where it would start an embedded expression
+
+	var out strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '\\' || ch == '"':
+			out.WriteString("\\" + string(ch))
+		case ch == '\n':
+			out.WriteString(`\n`)
+		case ch == '\t':
+			out.WriteString(`\t`)
+		case ch == '\r':
+			out.WriteString(`\r`)
+		case ch == '$' && i+1 < len(s) && s[i+1] == '{':
+			out.WriteString(`\$`)
+		default:
+			out.WriteByte(ch)
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,48 @@
+// lexer/fuzz_test.go
+
+package lexer
+
+import (
+	"monkey/token"
+	"testing"
+)
+
+func FuzzLexer(f *testing.F) {
+	// Checks that the lexer always reaches EOF on arbitrary input; every token other than EOF
+	// consumes at least one byte, so there can never be more tokens than bytes
+
+	seeds := []string{
+		"let five = 5;",
+		"let add = fn(x, y) { x + y; };",
+		"!-/*5; 5 < 10 > 5;",
+		"10 == 10; 10 != 9;",
+		"x / 2 // comment\n// another",
+		"\x00\xff\n\r\t",
+		`"" "a \"b\"" "unterminated`,
+		"`` `raw\n${x}` `open",
+		`'' 'a' '\n' '\'' 'ab`,
+		`"${}" "a ${b + "${c}"} d" "x ${ {y} } z" "${`,
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		l := New(input)
+
+		for i := 0; i <= len(input); i++ {
+			tok := l.NextToken()
+
+			if tok.Type == token.EOF {
+				return
+			}
+
+			if tok.Length <= 0 {
+				t.Fatalf("token %d consumes no input: %+v", i, tok)
+			}
+		}
+
+		t.Fatalf("lexer did not reach EOF after %d tokens", len(input)+1)
+	})
+}
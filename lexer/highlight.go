@@ -0,0 +1,73 @@
+// lexer/highlight.go
+
+package lexer
+
+import (
+	"monkey/token"
+)
+
+// How a token should be colored by a syntax highlighter
+type Category string
+
+const (
+	KEYWORD    Category = "keyword"
+	IDENTIFIER Category = "identifier"
+	LITERAL    Category = "literal" // Integers and strings
+	OPERATOR   Category = "operator"
+	DELIMITER  Category = "delimiter" // Punctuation such as parentheses, commas, and semicolons
+	COMMENT    Category = "comment"
+	INVALID    Category = "invalid" // Anything the lexer couldn't make sense of
+)
+
+type Highlight struct {
+	// A token along with its highlighting category; the token's position and length give the span
+	// of the source to color
+
+	token.Token
+	Category Category
+}
+
+func TokenizeAll(src string) []Highlight {
+	// Returns every token of the source, including comments, in order and without the final EOF;
+	// this never fails, since illegal input becomes INVALID tokens, which makes it suitable for
+	// highlighting code as it's being typed
+
+	var highlights []Highlight
+
+	l := New(src)
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		highlights = append(highlights, Highlight{Token: tok, Category: Categorize(tok)})
+	}
+
+	return highlights
+}
+
+func Categorize(tok token.Token) Category {
+	// Returns the highlighting category of a token; keywords added with token.RegisterKeyword are
+	// categorized as keywords too
+
+	switch tok.Type {
+	case token.IDENT:
+		return IDENTIFIER
+	case token.INT, token.CHAR, token.STRING, token.RAW_STRING, token.STRING_HEAD, token.STRING_MIDDLE,
+		token.STRING_TAIL:
+		return LITERAL
+	case token.COMMENT:
+		return COMMENT
+	case token.ILLEGAL:
+		return INVALID
+	case token.ASSIGN, token.PLUS, token.MINUS, token.BANG, token.ASTERISK, token.SLASH,
+		token.LT, token.GT, token.EQ, token.NOT_EQ, token.FAT_ARROW, token.ARROW:
+		return OPERATOR
+	case token.COMMA, token.SEMICOLON, token.COLON, token.LPAREN, token.RPAREN, token.LBRACE,
+		token.RBRACE, token.LBRACKET, token.RBRACKET:
+		return DELIMITER
+	}
+
+	if token.LookupIdent(tok.Literal) == tok.Type {
+		return KEYWORD
+	}
+
+	return INVALID
+}
@@ -0,0 +1,75 @@
+// lexer/highlight_test.go
+
+package lexer
+
+import (
+	"monkey/token"
+	"testing"
+)
+
+func TestTokenizeAll(t *testing.T) {
+	// Compares the categories and spans of every token in the input with the expected ones
+
+	input := "let s = \"hi\"; // greet\nif (s != 10) { true } @ \"open"
+
+	tests := []struct {
+		expectedText     string
+		expectedCategory Category
+		expectedPos      string
+	}{
+		{"let", KEYWORD, "1:1"},
+		{"s", IDENTIFIER, "1:5"},
+		{"=", OPERATOR, "1:7"},
+		{`"hi"`, LITERAL, "1:9"},
+		{";", DELIMITER, "1:13"},
+		{"// greet", COMMENT, "1:15"},
+		{"if", KEYWORD, "2:1"},
+		{"(", DELIMITER, "2:4"},
+		{"s", IDENTIFIER, "2:5"},
+		{"!=", OPERATOR, "2:7"},
+		{"10", LITERAL, "2:10"},
+		{")", DELIMITER, "2:12"},
+		{"{", DELIMITER, "2:14"},
+		{"true", KEYWORD, "2:16"},
+		{"}", DELIMITER, "2:21"},
+		{"@", INVALID, "2:23"},
+		{`"open`, INVALID, "2:25"},
+	}
+
+	highlights := TokenizeAll(input)
+
+	if len(highlights) != len(tests) {
+		t.Fatalf("wrong number of tokens. expected=%d, got=%d", len(tests), len(highlights))
+	}
+
+	for i, tt := range tests {
+		h := highlights[i]
+
+		if text := input[h.Pos.Offset:h.End()]; text != tt.expectedText {
+			t.Errorf("tests[%d] - text wrong. expected=%q, got=%q", i, tt.expectedText, text)
+		}
+
+		if h.Category != tt.expectedCategory {
+			t.Errorf("tests[%d] - category wrong. expected=%q, got=%q", i, tt.expectedCategory,
+				h.Category)
+		}
+
+		if h.Pos.String() != tt.expectedPos {
+			t.Errorf("tests[%d] - position wrong. expected=%q, got=%q", i, tt.expectedPos, h.Pos)
+		}
+	}
+}
+
+func init() {
+	token.RegisterKeyword("until", "UNTIL")
+}
+
+func TestCategorizeRegisteredKeyword(t *testing.T) {
+	// Keywords registered by dialects are highlighted like the built-in ones
+
+	tok := New("until").NextToken()
+
+	if c := Categorize(tok); c != KEYWORD {
+		t.Errorf("registered keyword category wrong. expected=%q, got=%q", KEYWORD, c)
+	}
+}
@@ -2,37 +2,112 @@
 
 package lexer
 
-import "monkey/token"
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"monkey/token"
+	"strings"
+)
 
 type Lexer struct {
-	input        string
-	position     int  // Current position in input (points to current char)
-	readPosition int  // Current reading position in input (after current char)
-	ch           byte // Current char under examination
+	input    io.ByteScanner // Source of the chars, read one at a time
+	err      error          // First error returned by the input, other than io.EOF
+	position int            // Current position in input (points to current char)
+	ch       byte           // Current char under examination
+	line     int            // Line of the current char, starting at 1
+	column   int            // Column of the current char, starting at 1
+
+	// Reused buffer for reading identifiers, numbers, and comments
+	literal []byte
+
+	// Describes each illegal token produced so far
+	errors []Error
+
+	// One entry for each `${` the lexer is inside of, counting the braces opened since then that
+	// haven't been closed; a `}` when the count is 0 resumes the string
+	interpolations []int
 }
 
 func New(input string) *Lexer {
 	// Creates a new Lexer and reads the first char
 
-	l := &Lexer{input: input}
+	return newLexer(strings.NewReader(input))
+}
+
+func NewFromReader(r io.Reader) *Lexer {
+	// Creates a new Lexer that reads its input incrementally, so large files and streams don't need
+	// to be read into memory up front
+
+	scanner, ok := r.(io.ByteScanner)
+	if !ok {
+		scanner = bufio.NewReader(r)
+	}
+
+	return newLexer(scanner)
+}
+
+func newLexer(input io.ByteScanner) *Lexer {
+	// Creates a new Lexer positioned before the start of the input and reads the first char
+
+	l := &Lexer{input: input, position: -1, line: 1}
 	l.readChar()
 	return l
 }
 
+func (l *Lexer) Reset(input string) {
+	// Makes the lexer start over on a new input while keeping its buffers, so lexing many small
+	// inputs doesn't need a new Lexer for each one
+
+	if r, ok := l.input.(*strings.Reader); ok {
+		r.Reset(input)
+	} else {
+		l.input = strings.NewReader(input)
+	}
+
+	l.err = nil
+	l.errors = l.errors[:0]
+	l.interpolations = l.interpolations[:0]
+	l.position = -1
+	l.ch = 0
+	l.line = 1
+	l.column = 0
+
+	l.readChar()
+}
+
+func (l *Lexer) Err() error {
+	// Returns the first error encountered while reading the input; the lexer treats errors like the
+	// end of the input, so this distinguishes a truncated read from a complete one
+
+	return l.err
+}
+
 func (l *Lexer) readChar() {
 	// Gives the next char and advances the cursor position
 
-	if l.readPosition >= len(l.input) {
+	// Moving past a newline starts a new line
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 0
+	}
+	l.column += 1
+
+	// Advance the current position
+	l.position += 1
+
+	ch, err := l.input.ReadByte()
+	if err != nil {
+		if err != io.EOF && l.err == nil {
+			l.err = err
+		}
+
 		// ASCII code for NULL is 0
 		l.ch = 0
-	} else {
-		l.ch = l.input[l.readPosition]
+		return
 	}
 
-	// Advance the current position
-	l.position = l.readPosition
-	// Advance the reading position
-	l.readPosition += 1
+	l.ch = ch
 }
 
 func (l *Lexer) NextToken() token.Token {
@@ -42,6 +117,9 @@ func (l *Lexer) NextToken() token.Token {
 
 	l.skipWhitespace()
 
+	// Remember where the token starts since reading it advances the cursor
+	pos := token.Position{Offset: l.position, Line: l.line, Column: l.column}
+
 	switch l.ch {
 	case '=':
 		if l.peekChar() == '=' {
@@ -52,13 +130,23 @@ func (l *Lexer) NextToken() token.Token {
 			// Concatenate the current assignment operator `=` and the subsequent `=`
 			literal := string(ch) + string(l.ch)
 			tok = token.Token{Type: token.EQ, Literal: literal}
+		} else if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.FAT_ARROW, Literal: string(ch) + string(l.ch)}
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '+':
 		tok = newToken(token.PLUS, l.ch)
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.ARROW, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = newToken(token.MINUS, l.ch)
+		}
 	case '!':
 		if l.peekChar() == '=' {
 			// Save l.ch in a local variable before calling l.readChar() again so we don't lose the
@@ -72,6 +160,11 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.BANG, l.ch)
 		}
 	case '/':
+		if l.peekChar() == '/' {
+			tok.Type = token.COMMENT
+			tok.Literal = l.readComment()
+			return l.finishToken(tok, pos)
+		}
 		tok = newToken(token.SLASH, l.ch)
 	case '*':
 		tok = newToken(token.ASTERISK, l.ch)
@@ -83,14 +176,37 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.SEMICOLON, l.ch)
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
+	case ':':
+		tok = newToken(token.COLON, l.ch)
 	case '(':
 		tok = newToken(token.LPAREN, l.ch)
 	case ')':
 		tok = newToken(token.RPAREN, l.ch)
 	case '{':
+		if n := len(l.interpolations); n > 0 {
+			l.interpolations[n-1]++
+		}
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
+		if n := len(l.interpolations); n > 0 && l.interpolations[n-1] == 0 {
+			// The embedded expression is finished, so carry on with the rest of the string
+			l.interpolations = l.interpolations[:n-1]
+			return l.finishToken(l.readStringPart(token.STRING_MIDDLE, token.STRING_TAIL, pos), pos)
+		}
+		if n := len(l.interpolations); n > 0 {
+			l.interpolations[n-1]--
+		}
 		tok = newToken(token.RBRACE, l.ch)
+	case '[':
+		tok = newToken(token.LBRACKET, l.ch)
+	case ']':
+		tok = newToken(token.RBRACKET, l.ch)
+	case '"':
+		return l.finishToken(l.readStringPart(token.STRING_HEAD, token.STRING, pos), pos)
+	case '`':
+		return l.finishToken(l.readRawString(pos), pos)
+	case '\'':
+		return l.finishToken(l.readCharLiteral(pos), pos)
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
@@ -98,17 +214,30 @@ func (l *Lexer) NextToken() token.Token {
 		if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
-			return tok
+			return l.finishToken(tok, pos)
 		} else if isDigit(l.ch) {
 			tok.Type = token.INT
 			tok.Literal = l.readNumber()
-			return tok
+			return l.finishToken(tok, pos)
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			tok = l.illegal(ILLEGAL_CHAR, pos, string(l.ch), fmt.Sprintf("illegal character %q", l.ch))
 		}
 	}
 
 	l.readChar()
+	return l.finishToken(tok, pos)
+}
+
+func (l *Lexer) finishToken(tok token.Token, pos token.Position) token.Token {
+	// Records where a token starts and how many bytes of input it spans; called once the lexer has
+	// moved past the token
+
+	tok.Pos = pos
+
+	if tok.Type != token.EOF {
+		tok.Length = l.position - pos.Offset
+	}
+
 	return tok
 }
 
@@ -121,11 +250,107 @@ func newToken(tokenType token.TokenType, ch byte) token.Token {
 func (l *Lexer) readIdentifier() string {
 	// Reads in an identifier and advances the lexer's position until encountering a non-letter char
 
-	position := l.position
+	l.literal = l.literal[:0]
 	for isLetter(l.ch) {
+		l.literal = append(l.literal, l.ch)
+		l.readChar()
+	}
+	return string(l.literal)
+}
+
+func (l *Lexer) readStringPart(open token.TokenType, closed token.TokenType,
+	pos token.Position) token.Token {
+	// Reads the text following the current `"` or `}` at `pos` and moves past the delimiter ending
+	// it; the token has type `open` if the text ends at a `${`, `closed` if it ends at the closing
+	// quote, and is illegal if the input ends first; escape sequences are left as written for the
+	// parser to interpret with Unescape
+
+	start := l.ch
+
+	l.literal = l.literal[:0]
+	for {
+		l.readChar()
+
+		switch {
+		case l.ch == '"':
+			l.readChar()
+			return token.Token{Type: closed, Literal: string(l.literal)}
+		case l.ch == '$' && l.peekChar() == '{':
+			l.readChar()
+			l.readChar()
+			l.interpolations = append(l.interpolations, 0)
+			return token.Token{Type: open, Literal: string(l.literal)}
+		case l.ch == 0:
+			return l.illegal(UNTERMINATED_STRING, pos, string(start)+string(l.literal),
+				"unterminated string")
+		case l.ch == '\\' && l.peekChar() != 0:
+			// Keep the escaped char so `\"` doesn't end the string and `\${` doesn't start an
+			// embedded expression
+			l.literal = append(l.literal, l.ch)
+			l.readChar()
+		}
+
+		l.literal = append(l.literal, l.ch)
+	}
+}
+
+func (l *Lexer) readRawString(pos token.Position) token.Token {
+	// Reads the text up to the backtick matching the current one and moves past it; the text is kept
+	// exactly as written, newlines included, and the token is illegal if the input ends first
+
+	l.literal = l.literal[:0]
+	for {
+		l.readChar()
+
+		switch l.ch {
+		case '`':
+			l.readChar()
+			return token.Token{Type: token.RAW_STRING, Literal: string(l.literal)}
+		case 0:
+			return l.illegal(UNTERMINATED_STRING, pos, "`"+string(l.literal), "unterminated raw string")
+		}
+
+		l.literal = append(l.literal, l.ch)
+	}
+}
+
+func (l *Lexer) readCharLiteral(pos token.Position) token.Token {
+	// Reads the text up to the next unescaped `'` on the same line and moves past it; the literal
+	// holds the text between the quotes with escapes left as written for the parser to interpret,
+	// and the token is illegal if the line or input ends first
+
+	l.literal = l.literal[:0]
+	for {
+		l.readChar()
+
+		switch l.ch {
+		case '\'':
+			l.readChar()
+			return token.Token{Type: token.CHAR, Literal: string(l.literal)}
+		case '\\':
+			// Keep the escaped char so `'\''` doesn't end at the escaped quote
+			l.literal = append(l.literal, l.ch)
+			l.readChar()
+		}
+
+		if l.ch == '\n' || l.ch == 0 {
+			return l.illegal(UNTERMINATED_CHAR, pos, "'"+string(l.literal),
+				"unterminated character literal")
+		}
+
+		l.literal = append(l.literal, l.ch)
+	}
+}
+
+func (l *Lexer) readComment() string {
+	// Reads a comment up to, but not including, the end of the line; trailing whitespace is dropped
+
+	l.literal = l.literal[:0]
+	for l.ch != '\n' && l.ch != 0 {
+		l.literal = append(l.literal, l.ch)
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return strings.TrimRight(string(l.literal), " \t\r")
 }
 
 func isLetter(ch byte) bool {
@@ -149,11 +374,12 @@ func (l *Lexer) skipWhitespace() {
 func (l *Lexer) readNumber() string {
 	// Reads in a number and advances the lexer's position until encountering a non-digit char
 
-	position := l.position
+	l.literal = l.literal[:0]
 	for isDigit(l.ch) {
+		l.literal = append(l.literal, l.ch)
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return string(l.literal)
 }
 
 func isDigit(ch byte) bool {
@@ -167,9 +393,13 @@ func isDigit(ch byte) bool {
 func (l *Lexer) peekChar() byte {
 	// Looks ahead by one char and returns it; similar to readChar() without incrementing the cursor
 
-	if l.readPosition >= len(l.input) {
+	ch, err := l.input.ReadByte()
+	if err != nil {
 		return 0
-	} else {
-		return l.input[l.readPosition]
 	}
+
+	// Put the char back so the next readChar() returns it again
+	l.input.UnreadByte()
+
+	return ch
 }
@@ -3,8 +3,12 @@
 package lexer
 
 import (
+	"errors"
+	"io"
 	"monkey/token"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestNextToken(t *testing.T) {
@@ -31,6 +35,12 @@ func TestNextToken(t *testing.T) {
 	
 	10 == 10;
 	10 != 9;
+	match (x) { _ => 1 }
+	s[1:2]
+	"foobar"
+	"foo bar"
+	import "lib/strings";
+	let n: int = 1 -> -1;
 	`
 
 	// Expected lexer output
@@ -111,6 +121,36 @@ func TestNextToken(t *testing.T) {
 		{token.NOT_EQ, "!="},
 		{token.INT, "9"},
 		{token.SEMICOLON, ";"},
+		{token.MATCH, "match"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "_"},
+		{token.FAT_ARROW, "=>"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.IDENT, "s"},
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COLON, ":"},
+		{token.INT, "2"},
+		{token.RBRACKET, "]"},
+		{token.STRING, "foobar"},
+		{token.STRING, "foo bar"},
+		{token.IMPORT, "import"},
+		{token.STRING, "lib/strings"},
+		{token.SEMICOLON, ";"},
+		{token.LET, "let"},
+		{token.IDENT, "n"},
+		{token.COLON, ":"},
+		{token.IDENT, "int"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.ARROW, "->"},
+		{token.MINUS, "-"},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
 		{token.EOF, ""},
 	}
 
@@ -130,3 +170,413 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestNextTokenPositions(t *testing.T) {
+	// Checks the line and column recorded for each token
+
+	input := "let x = 10;\n\tx != 5\n"
+
+	tests := []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"let", 1, 1},
+		{"x", 1, 5},
+		{"=", 1, 7},
+		{"10", 1, 9},
+		{";", 1, 11},
+		{"x", 2, 2},
+		{"!=", 2, 4},
+		{"5", 2, 7},
+		{"", 3, 1},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Pos.Line != tt.expectedLine || tok.Pos.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%s",
+				i, tt.expectedLine, tt.expectedColumn, tok.Pos)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	// Comments run to the end of the line and don't swallow a single slash
+
+	input := "x / 2; // halve x\n// next\ny"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "x"},
+		{token.SLASH, "/"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.COMMENT, "// halve x"},
+		{token.COMMENT, "// next"},
+		{token.IDENT, "y"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%s %q, got=%s %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestNewFromReader(t *testing.T) {
+	// A lexer reading a stream one byte at a time produces the same tokens as one reading a string
+
+	input := "let five = 5;\nlet ten = 10; // comment\nfive != ten;\n"
+
+	expected := New(input)
+	actual := NewFromReader(iotest.OneByteReader(strings.NewReader(input)))
+
+	for i := 0; ; i++ {
+		want := expected.NextToken()
+		got := actual.NextToken()
+
+		if got != want {
+			t.Fatalf("tokens[%d] wrong. expected=%+v, got=%+v", i, want, got)
+		}
+
+		if want.Type == token.EOF {
+			break
+		}
+	}
+
+	if err := actual.Err(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestReset(t *testing.T) {
+	// A reset lexer produces the same tokens, with the same positions, as a new one, whether it was
+	// reading a string or a stream before
+
+	first := "let x = 1;\nx + 2 // comment"
+	second := "let five = 5;\nlet ten = 10;\n"
+
+	lexers := []*Lexer{New(first), NewFromReader(iotest.OneByteReader(strings.NewReader(first)))}
+
+	for _, l := range lexers {
+		// Stop partway through so the lexer has state left over
+		for i := 0; i < 5; i++ {
+			l.NextToken()
+		}
+
+		l.Reset(second)
+		expected := New(second)
+
+		for i := 0; ; i++ {
+			want := expected.NextToken()
+			got := l.NextToken()
+
+			if got != want {
+				t.Fatalf("tokens[%d] wrong. expected=%+v, got=%+v", i, want, got)
+			}
+
+			if want.Type == token.EOF {
+				break
+			}
+		}
+	}
+}
+
+func TestNewFromReaderError(t *testing.T) {
+	// A failing read ends the token stream and is reported by Err()
+
+	readErr := errors.New("disk on fire")
+	l := NewFromReader(io.MultiReader(strings.NewReader("let x"), iotest.ErrReader(readErr)))
+
+	var types []token.TokenType
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		types = append(types, tok.Type)
+	}
+
+	if len(types) != 2 || types[0] != token.LET || types[1] != token.IDENT {
+		t.Errorf("wrong tokens before the error. got=%v", types)
+	}
+
+	if l.Err() != readErr {
+		t.Errorf("Err() wrong. expected=%v, got=%v", readErr, l.Err())
+	}
+}
+
+func TestTokenOffsets(t *testing.T) {
+	// Each token's offset and length map back to exactly its text in the input
+
+	input := "let x = 10;\n\tx != 5 // done  \n"
+
+	tests := []struct {
+		expectedOffset int
+		expectedLength int
+		expectedSource string
+	}{
+		{0, 3, "let"},
+		{4, 1, "x"},
+		{6, 1, "="},
+		{8, 2, "10"},
+		{10, 1, ";"},
+		{13, 1, "x"},
+		{15, 2, "!="},
+		{18, 1, "5"},
+		{20, 9, "// done  "},
+		{30, 0, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Pos.Offset != tt.expectedOffset || tok.Length != tt.expectedLength {
+			t.Fatalf("tests[%d] - range wrong. expected=%d+%d, got=%d+%d",
+				i, tt.expectedOffset, tt.expectedLength, tok.Pos.Offset, tok.Length)
+		}
+
+		if source := input[tok.Pos.Offset:tok.End()]; source != tt.expectedSource {
+			t.Fatalf("tests[%d] - source wrong. expected=%q, got=%q", i, tt.expectedSource, source)
+		}
+	}
+}
+
+func TestUnterminatedString(t *testing.T) {
+	// Checks that a string missing its closing quote becomes a single illegal token spanning the rest
+	// of the input
+
+	input := `let s = "abc`
+
+	l := New(input)
+	for i := 0; i < 3; i++ {
+		l.NextToken()
+	}
+
+	tok := l.NextToken()
+	if tok.Type != token.ILLEGAL {
+		t.Fatalf("tokentype wrong. expected=%q, got=%q", token.ILLEGAL, tok.Type)
+	}
+	if tok.Literal != `"abc` {
+		t.Errorf("literal wrong. expected=%q, got=%q", `"abc`, tok.Literal)
+	}
+	if got := input[tok.Pos.Offset:tok.End()]; got != `"abc` {
+		t.Errorf("source text wrong. expected=%q, got=%q", `"abc`, got)
+	}
+	if tok := l.NextToken(); tok.Type != token.EOF {
+		t.Errorf("tokentype wrong. expected=%q, got=%q", token.EOF, tok.Type)
+	}
+}
+
+func TestRawString(t *testing.T) {
+	// Checks that raw strings keep quotes, `${`, and newlines as written and track the lines they
+	// span
+
+	input := "`a \"b\" ${c}\n\td` x `open"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+	}{
+		{token.RAW_STRING, "a \"b\" ${c}\n\td", 1},
+		{token.IDENT, "x", 2},
+		{token.ILLEGAL, "`open", 2},
+		{token.EOF, "", 2},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+		if tok.Pos.Line != tt.expectedLine {
+			t.Fatalf("tests[%d] - line wrong. expected=%d, got=%d", i, tt.expectedLine, tok.Pos.Line)
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	// Checks that escaped quotes and `${` stay inside the string, with the escapes left as written,
+	// and that Escape undoes Unescape
+
+	input := `"a \"b\" \${c}\\" "d\n${e}\t"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedValue   string
+	}{
+		{token.STRING, `a \"b\" \${c}\\`, `a "b" ${c}\`},
+		{token.STRING_HEAD, `d\n`, "d\n"},
+		{token.IDENT, "e", "e"},
+		{token.STRING_TAIL, `\t`, "\t"},
+		{token.EOF, "", ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		value, err := Unescape(tok.Literal)
+		if err != nil || value != tt.expectedValue {
+			t.Errorf("tests[%d] - Unescape wrong. expected=%q, got=%q (err=%v)", i, tt.expectedValue,
+				value, err)
+		}
+		if escaped := Escape(value); escaped != tok.Literal {
+			t.Errorf("tests[%d] - Escape wrong. expected=%q, got=%q", i, tok.Literal, escaped)
+		}
+	}
+
+	for _, literal := range []string{`\q`, `a\`} {
+		if _, err := Unescape(literal); err == nil {
+			t.Errorf("Unescape(%q) did not return an error", literal)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	// Checks that each illegal token comes with an error describing it, in input order
+
+	input := "let @ = 'a;\nlet s = \"a ${x} b\n`raw"
+
+	expected := []Error{
+		{ILLEGAL_CHAR, token.Position{Offset: 4, Line: 1, Column: 5}, "illegal character '@'"},
+		{UNTERMINATED_CHAR, token.Position{Offset: 8, Line: 1, Column: 9},
+			"unterminated character literal"},
+		{UNTERMINATED_STRING, token.Position{Offset: 26, Line: 2, Column: 15}, "unterminated string"},
+	}
+
+	l := New(input)
+
+	var illegal []token.Token
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		if tok.Type == token.ILLEGAL {
+			illegal = append(illegal, tok)
+		}
+	}
+
+	if len(l.Errors()) != len(expected) || len(illegal) != len(expected) {
+		t.Fatalf("wrong number of errors. expected=%d, got=%d (%v), illegal tokens=%d",
+			len(expected), len(l.Errors()), l.Errors(), len(illegal))
+	}
+
+	for i, err := range l.Errors() {
+		if err != expected[i] {
+			t.Errorf("errors[%d] wrong. expected=%+v, got=%+v", i, expected[i], err)
+		}
+
+		if e, ok := l.ErrorAt(illegal[i]); !ok || e != err {
+			t.Errorf("ErrorAt(illegal[%d]) wrong. expected=%+v, got=%+v", i, err, e)
+		}
+	}
+}
+
+func TestCharLiteral(t *testing.T) {
+	// Checks that character literals keep their escapes as written and end at the line
+
+	input := `'a' '\'' '\\' 'é' 'ab' 'x
+'`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.CHAR, "a"},
+		{token.CHAR, `\'`},
+		{token.CHAR, `\\`},
+		{token.CHAR, "é"},
+		{token.CHAR, "ab"},
+		{token.ILLEGAL, "'x"},
+		{token.ILLEGAL, "'"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	// Compares the tokens of strings with embedded expressions, including braces and strings nested
+	// inside them, with the expected output
+
+	input := `"sum: ${a + b}!" "${ {} }${"in${x}"}" "$ {no}" "${x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedText    string
+	}{
+		{token.STRING_HEAD, "sum: ", `"sum: ${`},
+		{token.IDENT, "a", "a"},
+		{token.PLUS, "+", "+"},
+		{token.IDENT, "b", "b"},
+		{token.STRING_TAIL, "!", `}!"`},
+		{token.STRING_HEAD, "", `"${`},
+		{token.LBRACE, "{", "{"},
+		{token.RBRACE, "}", "}"},
+		{token.STRING_MIDDLE, "", `}${`},
+		{token.STRING_HEAD, "in", `"in${`},
+		{token.IDENT, "x", "x"},
+		{token.STRING_TAIL, "", `}"`},
+		{token.STRING_TAIL, "", `}"`},
+		{token.STRING, "$ {no}", `"$ {no}"`},
+		{token.STRING_HEAD, "", `"${`},
+		{token.IDENT, "x", "x"},
+		{token.EOF, "", ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%q %q, got=%q %q", i, tt.expectedType,
+				tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+
+		if text := input[tok.Pos.Offset:tok.End()]; text != tt.expectedText {
+			t.Errorf("tests[%d] - text wrong. expected=%q, got=%q", i, tt.expectedText, text)
+		}
+	}
+}
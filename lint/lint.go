@@ -0,0 +1,210 @@
+// lint/lint.go
+
+package lint
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/token"
+	"sort"
+)
+
+// Names of the checks, reported alongside each issue
+const (
+	UNUSED      = "unused"
+	UNREACHABLE = "unreachable"
+	SHADOW      = "shadow"
+	CONST       = "const"
+)
+
+type Issue struct {
+	// A probable mistake found in a program
+
+	Pos     token.Position
+	Check   string // Which check reported the issue
+	Message string
+}
+
+func (i Issue) Error() string {
+	// Returns the issue as "line:column: message (check)"
+
+	return fmt.Sprintf("%s: %s (%s)", i.Pos, i.Message, i.Check)
+}
+
+type binding struct {
+	// A name introduced by a let statement and whether anything refers to it
+
+	name     *ast.Identifier
+	used     bool
+	constant bool
+	depth    int // How many blocks deep the binding was made
+
+	// Whether the binding is part of the module's namespace, which holds the last top-level binding
+	// of each name; only set when linting a module, since exports can be used by importing modules
+	// and so are never reported as unused
+	exported bool
+}
+
+func Checks() []string {
+	// Returns the names of every check
+
+	return []string{UNUSED, UNREACHABLE, SHADOW, CONST}
+}
+
+type linter struct {
+	// Tracks the bindings currently in scope while walking a program
+
+	enabled  map[string]bool
+	module   bool // Whether top-level bindings are exports; see CheckModule
+	issues   []Issue
+	bindings []*binding
+	scope    map[string]*binding
+	depth    int
+}
+
+func Check(program *ast.Program) []Issue {
+	// Runs every check over a parsed program and returns the issues sorted by position
+
+	return CheckOnly(program, Checks()...)
+}
+
+func CheckOnly(program *ast.Program, checks ...string) []Issue {
+	// Runs only the named checks over a parsed program and returns the issues sorted by position
+
+	return check(program, false, checks)
+}
+
+func CheckModule(program *ast.Program, checks ...string) []Issue {
+	// Like CheckOnly, but treats the program as a module meant to be imported, whose top-level
+	// bindings are its exports and so aren't reported as unused
+
+	return check(program, true, checks)
+}
+
+func check(program *ast.Program, module bool, checks []string) []Issue {
+	// Runs the named checks over a parsed program, as a module if `module` is set
+
+	l := &linter{enabled: make(map[string]bool), module: module, scope: make(map[string]*binding)}
+
+	for _, check := range checks {
+		l.enabled[check] = true
+	}
+
+	l.statements(program.Statements)
+
+	for _, b := range l.bindings {
+		if !b.used && !b.exported {
+			l.report(b.name.Token.Pos, UNUSED, "%s declared and not used", b.name.Value)
+		}
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		a, b := l.issues[i].Pos, l.issues[j].Pos
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+
+	return l.issues
+}
+
+func (l *linter) report(pos token.Position, check string, format string, args ...any) {
+	// Records an issue if its check is enabled
+
+	if !l.enabled[check] {
+		return
+	}
+
+	l.issues = append(l.issues, Issue{Pos: pos, Check: check, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) statements(statements []ast.Statement) {
+	// Checks a sequence of statements; anything following a return can never run, which is reported
+	// once for the first such statement
+
+	returned := false
+
+	for _, s := range statements {
+		if returned {
+			l.report(s.Pos(), UNREACHABLE, "unreachable code after return")
+			returned = false
+		}
+
+		switch s := s.(type) {
+		case *ast.LetStatement:
+			// The value is checked first since it can still refer to an earlier binding of the
+			// same name, e.g. `let x = x + 1;`
+			l.uses(s.Value)
+			l.declare(s.Name, s.IsConst())
+		case *ast.ReturnStatement:
+			l.uses(s.ReturnValue)
+			returned = true
+		case *ast.ForStatement:
+			l.uses(s.Iterable)
+			l.block(s.Body, s.Key, s.Value)
+		case *ast.BlockStatement:
+			l.block(s)
+		default:
+			l.uses(s)
+		}
+	}
+}
+
+func (l *linter) block(b *ast.BlockStatement, names ...*ast.Identifier) {
+	// Checks a block in a scope of its own, with `names` declared at the start of it; bindings made
+	// inside the block are dropped from scope at the end of it
+
+	outer := l.scope
+	l.depth++
+
+	l.scope = make(map[string]*binding, len(outer))
+	for name, b := range outer {
+		l.scope[name] = b
+	}
+
+	for _, name := range names {
+		// `_` stands for a loop variable that isn't needed
+		if name != nil && name.Value != "_" {
+			l.declare(name, false)
+		}
+	}
+
+	l.statements(b.Statements)
+
+	l.scope = outer
+	l.depth--
+}
+
+func (l *linter) declare(name *ast.Identifier, constant bool) {
+	// Brings a new binding into scope, reporting if it hides an earlier one; constants can only be
+	// hidden by bindings in an inner block
+
+	if prev, ok := l.scope[name.Value]; ok {
+		if prev.constant && prev.depth == l.depth {
+			l.report(name.Token.Pos, CONST, "cannot reassign constant %s declared at %s",
+				name.Value, prev.name.Token.Pos)
+		} else {
+			l.report(name.Token.Pos, SHADOW, "%s shadows declaration at %s", name.Value,
+				prev.name.Token.Pos)
+		}
+	}
+
+	b := &binding{name: name, constant: constant, depth: l.depth, exported: l.module && l.depth == 0}
+	if prev, ok := l.scope[name.Value]; ok && b.exported {
+		prev.exported = false
+	}
+
+	l.bindings = append(l.bindings, b)
+	l.scope[name.Value] = b
+}
+
+func (l *linter) uses(node ast.Node) {
+	// Marks every binding referred to within a node as used
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Identifier); ok {
+			if b, ok := l.scope[ident.Value]; ok {
+				b.used = true
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,210 @@
+// lint/lint_test.go
+
+package lint
+
+import (
+	"io/fs"
+	"monkey/lexer"
+	"monkey/module"
+	"monkey/parser"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	// Compares the issues reported for monkey input with the expected ones
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			"let x = 1; x;",
+			[]string{},
+		},
+		{
+			"let x = 1; let y = 2; y;",
+			[]string{"1:5: x declared and not used (unused)"},
+		},
+		{
+			"let xs = 1; for (x in xs) { let y = 2; let z = x; z; }",
+			[]string{"1:33: y declared and not used (unused)"},
+		},
+		{
+			"let x = 1; let x = x + 1; x;",
+			[]string{"1:16: x shadows declaration at 1:5 (shadow)"},
+		},
+		{
+			"let x = 1; let x = 2; x;",
+			[]string{
+				"1:5: x declared and not used (unused)",
+				"1:16: x shadows declaration at 1:5 (shadow)",
+			},
+		},
+		{
+			"let x = 1;\nreturn x;\nx + 1;\nlet y = 2;",
+			[]string{
+				"3:1: unreachable code after return (unreachable)",
+				"4:5: y declared and not used (unused)",
+			},
+		},
+		{
+			"return 1; 2; 3;",
+			[]string{"1:11: unreachable code after return (unreachable)"},
+		},
+		{
+			"let xs = 1; for (_, x in xs) { x; }",
+			[]string{},
+		},
+		{
+			"let xs = 1; for (k, v in xs) { let xs = v; }",
+			[]string{
+				"1:18: k declared and not used (unused)",
+				"1:36: xs shadows declaration at 1:5 (shadow)",
+				"1:36: xs declared and not used (unused)",
+			},
+		},
+		{
+			"let xs = 1; for (x in xs) { let y = x; y; } let y = 2; y;",
+			[]string{},
+		},
+		{
+			"const x = 1; let x = 2; x;",
+			[]string{
+				"1:7: x declared and not used (unused)",
+				"1:18: cannot reassign constant x declared at 1:7 (const)",
+			},
+		},
+		{
+			"const x = 1; for (y in x) { const x = y; x; }",
+			[]string{"1:35: x shadows declaration at 1:7 (shadow)"},
+		},
+		{
+			"for (x in 1) { return x; x; }",
+			[]string{"1:26: unreachable code after return (unreachable)"},
+		},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser errors for %q: %v", tt.input, p.Errors())
+		}
+
+		issues := Check(program)
+
+		if len(issues) != len(tt.expected) {
+			t.Fatalf("wrong number of issues for %q. expected=%d, got=%d (%v)", tt.input,
+				len(tt.expected), len(issues), issues)
+		}
+
+		for i, issue := range issues {
+			if issue.Error() != tt.expected[i] {
+				t.Errorf("issues[%d] wrong for %q. expected=%q, got=%q", i, tt.input,
+					tt.expected[i], issue.Error())
+			}
+		}
+	}
+}
+
+func TestCheckOnly(t *testing.T) {
+	// Only the issues of the requested checks are reported
+
+	input := "let x = 1; let x = 2; return 1; x;"
+
+	tests := []struct {
+		checks   []string
+		expected []string
+	}{
+		{nil, []string{}},
+		{[]string{SHADOW}, []string{"1:16: x shadows declaration at 1:5 (shadow)"}},
+		{
+			[]string{UNUSED, UNREACHABLE},
+			[]string{
+				"1:5: x declared and not used (unused)",
+				"1:33: unreachable code after return (unreachable)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(input)).ParseProgram()
+
+		issues := CheckOnly(program, tt.checks...)
+
+		if len(issues) != len(tt.expected) {
+			t.Fatalf("wrong number of issues for %q. expected=%d, got=%d (%v)", tt.checks,
+				len(tt.expected), len(issues), issues)
+		}
+
+		for i, issue := range issues {
+			if issue.Error() != tt.expected[i] {
+				t.Errorf("issues[%d] wrong for %q. expected=%q, got=%q", i, tt.checks,
+					tt.expected[i], issue.Error())
+			}
+		}
+	}
+}
+
+func TestCheckModule(t *testing.T) {
+	// Top-level bindings are a module's exports, so only bindings in blocks and top-level ones that a
+	// later binding replaces are reported as unused
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 1; let y = 2; y;", []string{}},
+		{"let x = 1; let x = 2;", []string{"1:5: x declared and not used (unused)"}},
+		{
+			"let xs = 1; for (x in xs) { let y = x; }",
+			[]string{"1:33: y declared and not used (unused)"},
+		},
+	}
+
+	for _, tt := range tests {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+
+		issues := CheckModule(program, UNUSED)
+
+		if len(issues) != len(tt.expected) {
+			t.Fatalf("wrong number of issues for %q. expected=%d, got=%d (%v)", tt.input,
+				len(tt.expected), len(issues), issues)
+		}
+
+		for i, issue := range issues {
+			if issue.Error() != tt.expected[i] {
+				t.Errorf("issues[%d] wrong for %q. expected=%q, got=%q", i, tt.input,
+					tt.expected[i], issue.Error())
+			}
+		}
+	}
+}
+
+func TestCheckStdlib(t *testing.T) {
+	// The standard library lints clean as modules even though nothing in it uses its exports
+
+	files, err := fs.Glob(module.Stdlib, "std/*.monkey")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no standard library files found (err=%v)", err)
+	}
+
+	for _, file := range files {
+		src, err := fs.ReadFile(module.Stdlib, file)
+		if err != nil {
+			t.Fatalf("reading %s: %s", file, err)
+		}
+
+		p := parser.New(lexer.New(string(src)))
+		program := p.ParseProgram()
+
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser errors for %s: %v", file, p.Errors())
+		}
+
+		if issues := CheckModule(program, Checks()...); len(issues) != 0 {
+			t.Errorf("issues reported for %s: %v", file, issues)
+		}
+	}
+}
@@ -3,13 +3,50 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"monkey/repl"
 	"os"
 	"os/user"
+	"strings"
 )
 
+// Subcommands available as `monkey <name> [args]`; each returns the exit status
+var subcommands = map[string]func(args []string) int{
+	"ast":   runAst,
+	"check": runCheck,
+	"deps":  runDeps,
+	"fmt":   runFmt,
+	"lint":  runLint,
+}
+
 func main() {
+	// Anything other than a flag names a subcommand; flags configure the REPL
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		cmd, ok := subcommands[os.Args[1]]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "monkey: unknown command %q\n", os.Args[1])
+			os.Exit(2)
+		}
+
+		os.Exit(cmd(os.Args[2:]))
+	}
+
+	flags := flag.NewFlagSet("monkey", flag.ExitOnError)
+	verbose := flags.Bool("verbose", false, "print the parsed program after the tokens of each input")
+	tokensOnly := flags.Bool("tokens-only", false, "print only the tokens of each input (the default)")
+	flags.Parse(os.Args[1:])
+
+	if *verbose && *tokensOnly {
+		fmt.Fprintln(os.Stderr, "monkey: -verbose and -tokens-only can't be used together")
+		os.Exit(2)
+	}
+
+	var mode repl.Mode
+	if *verbose {
+		mode |= repl.Verbose
+	}
+
 	user, err := user.Current()
 
 	if err != nil {
@@ -18,5 +55,5 @@ func main() {
 
 	fmt.Printf("Hello %s! This is the Monkey programming language!\n", user.Username)
 	fmt.Printf("Feel free to type in commands\n")
-	repl.Start(os.Stdin, os.Stdout)
+	repl.StartWithMode(os.Stdin, os.Stdout, mode)
 }
@@ -0,0 +1,243 @@
+// module/module.go
+
+package module
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Extension of monkey source files; import paths leave it off
+const EXTENSION = ".monkey"
+
+// Environment variable holding extra module roots, separated like PATH
+const PATH_ENV = "MONKEYPATH"
+
+type Module struct {
+	// A parsed source file along with the modules it imports
+
+	Path    string // The import path, e.g. "lib/strings"
+	File    string // The file the module was read from
+	Program *ast.Program
+
+	// Names of the top-level `let` bindings, which make up the module's namespace, in source order
+	Exports []string
+
+	Imports []*Module
+}
+
+type ParseError struct {
+	// The parser errors of a module, along with its source so they can be shown in context
+
+	File   string
+	Source string
+	Err    error // The parser.ParserErrors joined together
+}
+
+func (e *ParseError) Error() string {
+	// Returns the file name followed by the parser errors
+
+	return e.File + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	// Gives errors.As access to the individual parser errors
+
+	return e.Err
+}
+
+type CycleError struct {
+	// An import that leads back to a module that is still being loaded
+
+	Cycle []string // Import paths from the first module in the cycle back to itself
+}
+
+func (e *CycleError) Error() string {
+	// Returns the cycle as "import cycle: a -> b -> a"
+
+	return "import cycle: " + strings.Join(e.Cycle, " -> ")
+}
+
+type Loader struct {
+	// Resolves import paths against a list of root directories and parses each module only once; a
+	// Loader can be shared between goroutines, which take turns loading so each module is still
+	// parsed only once
+
+	dirs     []string
+	fallback fs.FS // Searched after the directories, normally the embedded standard library
+
+	// Guards modules and loading; held for the whole of each Load, including the imports it loads
+	mu      sync.Mutex
+	modules map[string]*Module
+
+	// Import paths of the modules currently being loaded, outermost first, used to detect cycles
+	loading []string
+}
+
+func NewLoader(dirs ...string) *Loader {
+	// Creates a loader that resolves import paths against `dirs`, then the embedded standard library;
+	// earlier directories take precedence over later ones, and the current directory is used if none
+	// are given
+
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+
+	return &Loader{dirs: dirs, fallback: Stdlib, modules: map[string]*Module{}}
+}
+
+func SearchPath(dirs string) []string {
+	// Returns the module roots for a list of directories separated like PATH, e.g. from a
+	// command-line flag, followed by those in $MONKEYPATH; empty entries are dropped
+
+	var roots []string
+
+	for _, list := range []string{dirs, os.Getenv(PATH_ENV)} {
+		for _, dir := range filepath.SplitList(list) {
+			if dir != "" {
+				roots = append(roots, dir)
+			}
+		}
+	}
+
+	return roots
+}
+
+func (l *Loader) Dirs() []string {
+	// Returns the root directories in the order they are searched
+
+	return l.dirs
+}
+
+func cleanPath(importPath string) (string, error) {
+	// Returns the import path in canonical form, e.g. `./lib/x` as `lib/x`; paths that could name a
+	// file outside the root directories or the standard library, such as `../x` or `/x`, are
+	// rejected
+
+	clean := path.Clean(importPath)
+
+	if !fs.ValidPath(clean) || clean == "." || !filepath.IsLocal(filepath.FromSlash(clean)) {
+		return "", fmt.Errorf("cannot import %q: the path must be relative and stay inside its root "+
+			"directory (%w)", importPath, fs.ErrInvalid)
+	}
+
+	return clean, nil
+}
+
+func (l *Loader) Resolve(importPath string) (string, error) {
+	// Returns the file an import path refers to: the first root directory, in order, that contains
+	// it wins; files on disk shadow the embedded standard library, whose files are named
+	// `<embedded>/<path>.monkey`
+
+	importPath, err := cleanPath(importPath)
+	if err != nil {
+		return "", err
+	}
+
+	for _, dir := range l.dirs {
+		file := filepath.Join(dir, filepath.FromSlash(importPath)+EXTENSION)
+
+		info, err := os.Stat(file)
+		if err == nil && !info.IsDir() {
+			return file, nil
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("cannot import %q: %w", importPath, err)
+		}
+	}
+
+	name := importPath + EXTENSION
+	if info, err := fs.Stat(l.fallback, name); err == nil && !info.IsDir() {
+		return EMBEDDED + "/" + name, nil
+	}
+
+	return "", fmt.Errorf("cannot import %q: %w in %s", importPath, fs.ErrNotExist,
+		strings.Join(l.dirs, string(filepath.ListSeparator)))
+}
+
+func (l *Loader) Load(importPath string) (*Module, error) {
+	// Returns the module at `importPath` along with everything it imports; modules are cached, so
+	// importing the same path from several places yields the same *Module
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.load(importPath)
+}
+
+func (l *Loader) load(importPath string) (*Module, error) {
+	// Implements Load with the lock held, calling itself for each import
+
+	importPath, err := cleanPath(importPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if m, ok := l.modules[importPath]; ok {
+		return m, nil
+	}
+
+	for i, loading := range l.loading {
+		if loading == importPath {
+			cycle := append([]string{}, l.loading[i:]...)
+			return nil, &CycleError{Cycle: append(cycle, importPath)}
+		}
+	}
+
+	file, err := l.Resolve(importPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var src []byte
+	if name, ok := strings.CutPrefix(file, EMBEDDED+"/"); ok {
+		src, err = fs.ReadFile(l.fallback, name)
+	} else {
+		src, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot import %q: %w", importPath, err)
+	}
+
+	p := parser.New(lexer.New(string(src)))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		errs := make([]error, len(p.Errors()))
+		for i, err := range p.Errors() {
+			errs[i] = err
+		}
+		return nil, &ParseError{File: file, Source: string(src), Err: errors.Join(errs...)}
+	}
+
+	m := &Module{Path: importPath, File: file, Program: program}
+
+	l.loading = append(l.loading, importPath)
+	defer func() { l.loading = l.loading[:len(l.loading)-1] }()
+
+	for _, s := range program.Statements {
+		switch s := s.(type) {
+		case *ast.LetStatement:
+			m.Exports = append(m.Exports, s.Name.Value)
+		case *ast.ImportStatement:
+			imported, err := l.load(s.Path.Value)
+			if err != nil {
+				return nil, err
+			}
+			m.Imports = append(m.Imports, imported)
+		}
+	}
+
+	l.modules[importPath] = m
+
+	return m, nil
+}
@@ -0,0 +1,238 @@
+// module/module_test.go
+
+package module
+
+import (
+	"errors"
+	"io/fs"
+	"monkey/parser"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	// Writes each source file into a fresh temporary directory and returns the directory
+
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, src := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	// Checks that a module and its imports are loaded with their exports
+
+	dir := writeFiles(t, map[string]string{
+		"main.monkey":        `import "lib/strings"; import "math"; let x = 1;`,
+		"math.monkey":        `let pi = 3; let tau = pi * 2;`,
+		"lib/strings.monkey": `import "math"; let sep = "/";`,
+		"lib/unused.monkey":  `let y = ;`, // Never imported, so its error goes unnoticed
+	})
+
+	loader := NewLoader(dir)
+
+	m, err := loader.Load("main")
+	if err != nil {
+		t.Fatalf("Load returned error: %s", err)
+	}
+
+	if !reflect.DeepEqual(m.Exports, []string{"x"}) {
+		t.Errorf("m.Exports wrong. expected=%q, got=%q", []string{"x"}, m.Exports)
+	}
+
+	if len(m.Imports) != 2 {
+		t.Fatalf("m.Imports does not contain 2 modules. got=%d", len(m.Imports))
+	}
+
+	strings, math := m.Imports[0], m.Imports[1]
+
+	if strings.Path != "lib/strings" || math.Path != "math" {
+		t.Errorf("import paths wrong. got=%q, %q", strings.Path, math.Path)
+	}
+
+	if !reflect.DeepEqual(math.Exports, []string{"pi", "tau"}) {
+		t.Errorf("math.Exports wrong. expected=%q, got=%q", []string{"pi", "tau"}, math.Exports)
+	}
+
+	// Both importers of "math" share the module that was parsed once
+	if len(strings.Imports) != 1 || strings.Imports[0] != math {
+		t.Errorf("lib/strings does not share the cached math module")
+	}
+
+	again, err := loader.Load("./main")
+	if err != nil || again != m {
+		t.Errorf("loading main again did not return the cached module (err=%v)", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	// Checks that missing files and parse errors in imported modules are reported
+
+	dir := writeFiles(t, map[string]string{
+		"missing.monkey": `import "nowhere";`,
+		"broken.monkey":  `import "bad";`,
+		"bad.monkey":     `let = 1;`,
+	})
+
+	loader := NewLoader(dir)
+
+	if _, err := loader.Load("missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a not-exist error. got=%v", err)
+	}
+
+	_, err := loader.Load("broken")
+
+	var pe parser.ParserError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected a parser error. got=%v", err)
+	}
+
+	if pe.Pos.Line != 1 || pe.Pos.Column != 5 {
+		t.Errorf("error position wrong. got=%s", pe.Pos)
+	}
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Source != `let = 1;` {
+		t.Errorf("expected a ParseError with the source of bad.monkey. got=%v", err)
+	}
+}
+
+func TestLoadOutsideRoot(t *testing.T) {
+	// Checks that import paths can't reach files outside the root directories, even ones that exist
+
+	dir := writeFiles(t, map[string]string{
+		"root/main.monkey":   `import "../secret";`,
+		"root/lib/x.monkey":  `let x = 1;`,
+		"secret.monkey":      `let secret = 1;`,
+		"root/other.monkey":  `import "lib/../lib/x";`,
+		"root/sneaky.monkey": `import "lib/../../secret";`,
+	})
+
+	loader := NewLoader(filepath.Join(dir, "root"))
+
+	for _, importPath := range []string{"../secret", "/secret", "main", "sneaky", "."} {
+		if _, err := loader.Load(importPath); !errors.Is(err, fs.ErrInvalid) {
+			t.Errorf("Load(%q) wrong. expected an invalid path error, got=%v", importPath, err)
+		}
+	}
+
+	if _, err := loader.Resolve("../secret"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("Resolve wrong. expected an invalid path error, got=%v", err)
+	}
+
+	// Paths that leave a directory but stay inside the root are fine
+	if _, err := loader.Load("other"); err != nil {
+		t.Errorf("Load(%q) returned error: %s", "other", err)
+	}
+}
+
+func TestConcurrentLoad(t *testing.T) {
+	// A Loader shared between goroutines parses each module once and gives every goroutine the same
+	// *Module, while separate loaders work independently; run with -race to check
+
+	dir := writeFiles(t, map[string]string{
+		"main.monkey": `import "math"; import "std/strings"; let x = 1;`,
+		"math.monkey": `let pi = 3;`,
+	})
+
+	shared := NewLoader(dir)
+
+	modules := make([]*Module, 8)
+
+	var wg sync.WaitGroup
+
+	for i := range modules {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			m, err := shared.Load("main")
+			if err != nil {
+				t.Errorf("Load returned error: %s", err)
+				return
+			}
+			modules[i] = m
+
+			if _, err := NewLoader(dir).Load("main"); err != nil {
+				t.Errorf("Load with a separate loader returned error: %s", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for i, m := range modules {
+		if m != modules[0] {
+			t.Errorf("modules[%d] is not the module loaded by the others", i)
+		}
+	}
+}
+
+func TestSearchPath(t *testing.T) {
+	// Checks that modules are resolved against the roots in order, so earlier roots shadow later ones
+
+	first := writeFiles(t, map[string]string{
+		"a.monkey": `import "b"; let fromFirst = 1;`,
+	})
+	second := writeFiles(t, map[string]string{
+		"a.monkey": `let fromSecond = 1;`,
+		"b.monkey": `let b = 1;`,
+	})
+
+	m, err := NewLoader(first, second).Load("a")
+	if err != nil {
+		t.Fatalf("Load returned error: %s", err)
+	}
+
+	if m.File != filepath.Join(first, "a.monkey") {
+		t.Errorf("m.File wrong. expected=%q, got=%q", filepath.Join(first, "a.monkey"), m.File)
+	}
+
+	if len(m.Imports) != 1 || m.Imports[0].File != filepath.Join(second, "b.monkey") {
+		t.Errorf("b was not resolved in the second root")
+	}
+
+	t.Setenv(PATH_ENV, first+string(filepath.ListSeparator)+second)
+
+	expected := []string{"lib", first, second}
+	if roots := SearchPath("lib" + string(filepath.ListSeparator)); !reflect.DeepEqual(roots, expected) {
+		t.Errorf("SearchPath wrong. expected=%q, got=%q", expected, roots)
+	}
+}
+
+func TestImportCycle(t *testing.T) {
+	// Checks that a cycle of imports is reported with every module on it
+
+	dir := writeFiles(t, map[string]string{
+		"main.monkey": `import "a";`,
+		"a.monkey":    `import "b";`,
+		"b.monkey":    `import "c";`,
+		"c.monkey":    `import "a";`,
+	})
+
+	_, err := NewLoader(dir).Load("main")
+
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("expected a CycleError. got=%v", err)
+	}
+
+	expected := "import cycle: a -> b -> c -> a"
+	if err.Error() != expected {
+		t.Errorf("error wrong. expected=%q, got=%q", expected, err.Error())
+	}
+}
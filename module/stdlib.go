@@ -0,0 +1,20 @@
+// module/stdlib.go
+
+package module
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// Name that embedded files are given in place of a directory, like `<stdin>` for standard input
+const EMBEDDED = "<embedded>"
+
+// The standard library, imported as e.g. `import "std/strings";`; it is compiled into the binary so
+// it's available without any files on disk
+//
+//go:embed std/*.monkey
+var stdlib embed.FS
+
+// The standard library as a file system, with import paths as file names minus the extension
+var Stdlib fs.FS = stdlib
@@ -0,0 +1,52 @@
+// module/stdlib_test.go
+
+package module
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStdlibParses(t *testing.T) {
+	// Loads every module of the embedded standard library to make sure it parses
+
+	files, err := fs.Glob(Stdlib, "std/*"+EXTENSION)
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no standard library modules found (err=%v)", err)
+	}
+
+	loader := NewLoader(t.TempDir())
+
+	for _, file := range files {
+		importPath := strings.TrimSuffix(file, EXTENSION)
+
+		m, err := loader.Load(importPath)
+		if err != nil {
+			t.Errorf("Load(%q) returned error: %s", importPath, err)
+			continue
+		}
+
+		if m.File != EMBEDDED+"/"+file {
+			t.Errorf("m.File wrong. expected=%q, got=%q", EMBEDDED+"/"+file, m.File)
+		}
+	}
+}
+
+func TestStdlibShadowedByDisk(t *testing.T) {
+	// Checks that a module on disk takes precedence over the embedded one with the same path
+
+	dir := writeFiles(t, map[string]string{
+		"std/strings.monkey": `let local = 1;`,
+	})
+
+	m, err := NewLoader(dir).Load("std/strings")
+	if err != nil {
+		t.Fatalf("Load returned error: %s", err)
+	}
+
+	if m.File != filepath.Join(dir, "std", "strings.monkey") {
+		t.Errorf("m.File wrong. got=%q", m.File)
+	}
+}
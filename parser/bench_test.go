@@ -0,0 +1,101 @@
+// parser/bench_test.go
+
+package parser
+
+import (
+	"fmt"
+	"monkey/lexer"
+	"strings"
+	"testing"
+)
+
+func benchmarkInput(lines int) string {
+	// Builds a program mixing bindings, operators of every precedence, and comments
+
+	var out strings.Builder
+
+	for i := 0; i < lines; i++ {
+		name := benchmarkName(i)
+
+		fmt.Fprintf(&out, "let %s = a * %d + b / c - -d; // line %d\n", name, i, i)
+		fmt.Fprintf(&out, "%s < 10 == !%s > 100;\n", name, name)
+		fmt.Fprintf(&out, "return %s != 0;\n", name)
+	}
+
+	return out.String()
+}
+
+func benchmarkName(i int) string {
+	// Returns a distinct identifier for each index; identifiers can't contain digits
+
+	name := "value_"
+
+	for {
+		name += string(rune('a' + i%26))
+		i /= 26
+		if i == 0 {
+			return name
+		}
+	}
+}
+
+func BenchmarkParseProgram(b *testing.B) {
+	// Measures parsing a large program
+
+	input := benchmarkInput(1000)
+	b.SetBytes(int64(len(input)))
+
+	// Make sure the benchmark measures the happy path rather than error recovery
+	p := New(lexer.New(input))
+	p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		b.Fatalf("benchmark input has parser errors: %v", p.Errors()[0])
+	}
+
+	for i := 0; i < b.N; i++ {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+	}
+}
+
+func BenchmarkParseProgramComments(b *testing.B) {
+	// Measures parsing a large program while keeping its comments
+
+	input := benchmarkInput(1000)
+	b.SetBytes(int64(len(input)))
+
+	for i := 0; i < b.N; i++ {
+		p := NewWithMode(lexer.New(input), ParseComments)
+		p.ParseProgram()
+	}
+}
+
+func BenchmarkParseSmallInputs(b *testing.B) {
+	// Measures parsing many one-line inputs, like the REPL does
+
+	inputs := strings.Split(benchmarkInput(100), "\n")
+
+	for i := 0; i < b.N; i++ {
+		for _, input := range inputs {
+			p := New(lexer.New(input))
+			p.ParseProgram()
+		}
+	}
+}
+
+func BenchmarkParseSmallInputsReset(b *testing.B) {
+	// Measures parsing many one-line inputs with a single lexer and parser that are reset each time
+
+	inputs := strings.Split(benchmarkInput(100), "\n")
+
+	l := lexer.New("")
+	p := New(l)
+
+	for i := 0; i < b.N; i++ {
+		for _, input := range inputs {
+			l.Reset(input)
+			p.Reset(l)
+			p.ParseProgram()
+		}
+	}
+}
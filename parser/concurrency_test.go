@@ -0,0 +1,99 @@
+// parser/concurrency_test.go
+
+package parser
+
+import (
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/token"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// Counts the keywords and operators registered by TestConcurrentRegistration, so each run of the
+// test registers new ones; registering the same keyword twice panics
+var registrations atomic.Int64
+
+func TestConcurrentParsing(t *testing.T) {
+	// Separate parsers share no mutable state, so they can run in different goroutines while
+	// keywords are being looked up; run with -race to check
+
+	input := benchmarkInput(50)
+
+	p := New(lexer.New(input))
+	expected := ast.Dump(p.ParseProgram())
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			p := NewWithMode(lexer.New(input), ParseComments)
+			if actual := ast.Dump(p.ParseProgram()); actual != expected {
+				t.Errorf("concurrent parse produced a different tree")
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			token.Keywords()
+		}
+	}()
+
+	wg.Wait()
+}
+
+func TestConcurrentRegistration(t *testing.T) {
+	// Keywords and operators can be registered while other goroutines lex and parse; the input
+	// doesn't use them, so every parse produces the same tree; run with -race to check
+
+	input := benchmarkInput(50)
+	expected := ast.Dump(New(lexer.New(input)).ParseProgram())
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Reusing one lexer and parser per goroutine also covers Reset
+			l := lexer.New(input)
+			p := New(l)
+
+			for j := 0; j < 5; j++ {
+				if actual := ast.Dump(p.ParseProgram()); actual != expected {
+					t.Errorf("parse during registration produced a different tree")
+					return
+				}
+				l.Reset(input)
+				p.Reset(l)
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 20; i++ {
+			n := int(registrations.Add(1))
+			name := "registered_" + benchmarkName(n)
+			typ := token.TokenType("REGISTERED_" + benchmarkName(n))
+
+			token.RegisterKeyword(name, typ)
+			RegisterInfixOperator(typ, SUM, (*Parser).ParseInfixExpression)
+			RegisterPrefixOperator(typ, (*Parser).ParsePrefixExpression)
+			OperatorPrecedence(typ)
+		}
+	}()
+
+	wg.Wait()
+}
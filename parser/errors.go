@@ -0,0 +1,66 @@
+// parser/errors.go
+
+package parser
+
+import (
+	"fmt"
+	"monkey/token"
+	"strings"
+)
+
+type ParserError struct {
+	// An error encountered while parsing along with where it happened
+
+	Message string
+	Token   token.Token    // The token the error refers to
+	Pos     token.Position // Where the error happened, usually the position of Token
+
+	// The token type the parser was looking for, if it expected a specific one, and the type it
+	// found instead
+	Expected token.TokenType
+	Got      token.TokenType
+}
+
+func (e ParserError) Error() string {
+	// Returns the error as "line:column: message"
+
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+}
+
+func (e ParserError) Format(source string) string {
+	// Returns the error followed by the offending source line and a caret under the bad token
+
+	return e.Error() + "\n" + Excerpt(source, e.Pos)
+}
+
+func Excerpt(source string, pos token.Position) string {
+	// Returns the line of the source at `pos` with a `^` caret on the line below pointing at the
+	// column; returns an empty string if the position is outside of the source
+
+	if !pos.IsValid() {
+		return ""
+	}
+
+	lines := strings.Split(source, "\n")
+	if pos.Line > len(lines) {
+		return ""
+	}
+
+	line := strings.TrimRight(lines[pos.Line-1], "\r")
+
+	// Pad with the same whitespace as the source line so the caret lines up even when tabs are used;
+	// the column may be one past the end of the line when pointing at EOF
+	var padding strings.Builder
+	for i := 0; i < pos.Column-1 && i < len(line); i++ {
+		if line[i] == '\t' {
+			padding.WriteByte('\t')
+		} else {
+			padding.WriteByte(' ')
+		}
+	}
+	for i := len(line); i < pos.Column-1; i++ {
+		padding.WriteByte(' ')
+	}
+
+	return fmt.Sprintf("    %s\n    %s^\n", line, padding.String())
+}
@@ -0,0 +1,138 @@
+// parser/errors_test.go
+
+package parser
+
+import (
+	"monkey/lexer"
+	"monkey/token"
+	"testing"
+)
+
+func TestParserErrorPositions(t *testing.T) {
+	// Checks that errors point at the offending token and render an excerpt with a caret
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"let = 5;",
+			"1:5: expected next token to be IDENT, got = instead\n" +
+				"    let = 5;\n" +
+				"        ^\n",
+		},
+		{
+			"let x = 1;\n\tlet y 2;",
+			"2:8: expected next token to be =, got INT instead\n" +
+				"    \tlet y 2;\n" +
+				"    \t      ^\n",
+		},
+		{
+			"let x",
+			"1:6: expected next token to be =, got EOF instead\n" +
+				"    let x\n" +
+				"         ^\n",
+		},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Fatalf("expected errors for %q. got none", tt.input)
+		}
+
+		if actual := errors[0].Format(tt.input); actual != tt.expected {
+			t.Errorf("wrong error for %q.\nexpected=%q\ngot=%q", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestParserErrorRecovery(t *testing.T) {
+	// Independent errors are all reported in one pass and valid statements around them still parse
+
+	tests := []struct {
+		input              string
+		expectedErrors     []string
+		expectedStatements string
+	}{
+		{
+			"let = 5; let y = 10; let 7; y;",
+			[]string{
+				"1:5: expected next token to be IDENT, got = instead",
+				"1:26: expected next token to be IDENT, got INT instead",
+			},
+			"let y = 10;y",
+		},
+		{
+			"let x 5 let y = 1; return ;; 2 + 3",
+			[]string{
+				"1:7: expected next token to be =, got INT instead",
+				"1:27: no prefix parse function for ; found",
+			},
+			"let y = 1;(2 + 3)",
+		},
+		{
+			"let x = 5",
+			[]string{},
+			"let x = 5;",
+		},
+		{
+			"1 + ",
+			[]string{"1:5: no prefix parse function for EOF found"},
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.expectedErrors) {
+			t.Fatalf("wrong number of errors for %q. expected=%d, got=%d (%v)", tt.input,
+				len(tt.expectedErrors), len(errors), errors)
+		}
+
+		for i, err := range errors {
+			if err.Error() != tt.expectedErrors[i] {
+				t.Errorf("errors[%d] wrong. expected=%q, got=%q", i, tt.expectedErrors[i], err.Error())
+			}
+		}
+
+		if program.String() != tt.expectedStatements {
+			t.Errorf("program wrong. expected=%q, got=%q", tt.expectedStatements, program.String())
+		}
+	}
+}
+
+func TestStructuredParserErrors(t *testing.T) {
+	// Checks the machine-readable fields of parser errors
+
+	input := "let 5 = x; 1 + ;"
+
+	p := New(lexer.New(input))
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) != 2 {
+		t.Fatalf("expected 2 errors. got=%d (%v)", len(errors), errors)
+	}
+
+	if errors[0].Expected != token.IDENT || errors[0].Got != token.INT ||
+		errors[0].Token.Literal != "5" || errors[0].Pos != (token.Position{Offset: 4, Line: 1, Column: 5}) {
+		t.Errorf("errors[0] wrong. got=%+v", errors[0])
+	}
+
+	if errors[1].Expected != "" || errors[1].Got != token.SEMICOLON ||
+		errors[1].Pos != (token.Position{Offset: 15, Line: 1, Column: 16}) {
+		t.Errorf("errors[1] wrong. got=%+v", errors[1])
+	}
+
+	messages := p.ErrorStrings()
+	if len(messages) != 2 || messages[0] != "expected next token to be IDENT, got INT instead" {
+		t.Errorf("ErrorStrings() wrong. got=%q", messages)
+	}
+}
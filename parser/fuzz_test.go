@@ -0,0 +1,44 @@
+// parser/fuzz_test.go
+
+package parser
+
+import (
+	"monkey/ast"
+	"monkey/lexer"
+	"testing"
+)
+
+func FuzzParseProgram(f *testing.F) {
+	// Checks that the parser never panics on arbitrary input, and that the resulting tree can
+	// always be printed
+
+	seeds := []string{
+		"let x = 5;",
+		"let x = 5",
+		"return a + b * c;",
+		"-a * b; !-a;",
+		"let = 5; let 7; 1 + ;",
+		"// comment\nlet x = 1; // trailing",
+		"3 + 4; -5 * 5",
+		"let let let",
+		"return",
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		for _, mode := range []Mode{0, ParseComments} {
+			p := NewWithMode(lexer.New(input), mode)
+			program := p.ParseProgram()
+
+			_ = program.String()
+			_ = ast.Dump(program)
+
+			for _, err := range p.Errors() {
+				_ = err.Format(input)
+			}
+		}
+	})
+}
@@ -0,0 +1,77 @@
+// parser/golden_test.go
+
+package parser
+
+import (
+	"flag"
+	"monkey/ast"
+	"monkey/lexer"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Run `go test ./parser -update` to rewrite the golden files after an intended change in output
+var update = flag.Bool("update", false, "update the .golden files in testdata")
+
+func TestGoldenFiles(t *testing.T) {
+	// Parses every testdata/*.monkey file and compares the AST dump, followed by any parser errors,
+	// with the matching .golden file
+
+	files, err := filepath.Glob(filepath.Join("testdata", "*.monkey"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) == 0 {
+		t.Fatalf("no .monkey files found in testdata")
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual := goldenOutput(string(src))
+			golden := strings.TrimSuffix(file, ".monkey") + ".golden"
+
+			if *update {
+				if err := os.WriteFile(golden, []byte(actual), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%s (run with -update to create it)", err)
+			}
+
+			if actual != string(expected) {
+				t.Errorf("output does not match %s (run with -update if this is intended)\n"+
+					"expected:\n%s\ngot:\n%s", golden, expected, actual)
+			}
+		})
+	}
+}
+
+func goldenOutput(src string) string {
+	// Returns the text compared against a golden file
+
+	p := New(lexer.New(src))
+	program := p.ParseProgram()
+
+	var out strings.Builder
+
+	out.WriteString(ast.Dump(program))
+	out.WriteString("\n")
+
+	for _, err := range p.Errors() {
+		out.WriteString("error: " + err.Error() + "\n")
+	}
+
+	return out.String()
+}
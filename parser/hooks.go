@@ -0,0 +1,136 @@
+// parser/hooks.go
+
+package parser
+
+import (
+	"monkey/ast"
+	"monkey/token"
+	"sync"
+)
+
+// Parses a statement starting at the current token and leaves the parser on its last token
+type statementParseFn func() ast.Statement
+
+type infixOperator struct {
+	// An operator added with RegisterInfixOperator
+
+	precedence int
+	fn         func(p *Parser, left ast.Expression) ast.Expression
+}
+
+// Parse functions and precedences for the operators added with RegisterPrefixOperator and
+// RegisterInfixOperator; each parser takes a copy when it's created, so later registrations don't
+// affect it
+var (
+	prefixOperators = map[token.TokenType]func(p *Parser) ast.Expression{}
+	infixOperators  = map[token.TokenType]infixOperator{}
+)
+
+// Guards the operator maps, which can be added to while other goroutines create parsers
+var operatorsMu sync.RWMutex
+
+func RegisterPrefixOperator(t token.TokenType, fn func(p *Parser) ast.Expression) {
+	// Makes parsers created from now on call `fn` for expressions that start with a token of type
+	// `t`, so dialects can extend the grammar without editing this package; `fn` starts on that
+	// token and leaves the parser on the last token of the expression; registering a type again,
+	// including a built-in one, replaces its function
+
+	operatorsMu.Lock()
+	defer operatorsMu.Unlock()
+
+	prefixOperators[t] = fn
+}
+
+func RegisterInfixOperator(t token.TokenType, precedence int,
+	fn func(p *Parser, left ast.Expression) ast.Expression) {
+	// Makes parsers created from now on treat tokens of type `t` that follow an expression as an
+	// infix operator binding with `precedence`, one of the precedence constants, and call `fn` to
+	// parse it; `fn` receives the expression on the left with the parser on the operator and leaves
+	// it on the last token of the whole expression; registering a type again, including a built-in
+	// one, replaces its function and precedence for parsers created afterwards and for
+	// OperatorPrecedence
+
+	operatorsMu.Lock()
+	defer operatorsMu.Unlock()
+
+	infixOperators[t] = infixOperator{precedence: precedence, fn: fn}
+}
+
+func (p *Parser) RegisterStatement(t token.TokenType, fn func() ast.Statement) {
+	// Makes the parser call `fn` for statements that start with a token of type `t`, typically a
+	// keyword added with token.RegisterKeyword; registering a type again replaces its function
+
+	p.statementParseFns[t] = fn
+}
+
+// The methods below expose the parser's cursor and helpers to functions registered from outside
+// the package
+
+func (p *Parser) CurToken() token.Token {
+	// Returns the token currently being parsed
+
+	return p.curToken
+}
+
+func (p *Parser) PeekToken() token.Token {
+	// Returns the token after the current one
+
+	return p.peekToken
+}
+
+func (p *Parser) PeekTokenN(n int) token.Token {
+	// Returns the token `n` places after the current one, so PeekTokenN(1) is PeekToken(); if `n` is
+	// outside 1 to MAX_LOOKAHEAD, returns an ILLEGAL token with an empty literal instead
+
+	if n < 1 || n > MAX_LOOKAHEAD {
+		return token.Token{Type: token.ILLEGAL}
+	}
+
+	return p.peekN(n)
+}
+
+func (p *Parser) NextToken() {
+	// Advances to the next token
+
+	p.nextToken()
+}
+
+func (p *Parser) ExpectPeek(t token.TokenType) bool {
+	// Advances if the next token is of type `t`, otherwise records an error and returns false
+
+	return p.expectPeek(t)
+}
+
+func (p *Parser) Precedence(t token.TokenType) int {
+	// Returns the precedence this parser gives an infix operator token, which includes the operators
+	// registered when it was created; if a match isn't found, returns lowest
+
+	return p.precedence(t)
+}
+
+func (p *Parser) ParseExpression(precedence int) ast.Expression {
+	// Parses an expression starting at the current token; see the precedence constants
+
+	return p.parseExpression(precedence)
+}
+
+func (p *Parser) ParsePrefixExpression() ast.Expression {
+	// Parses the current token as a prefix operator applied to the expression after it, building an
+	// *ast.PrefixExpression like those for `-` and `!`
+
+	return p.parsePrefixExpression()
+}
+
+func (p *Parser) ParseInfixExpression(left ast.Expression) ast.Expression {
+	// Parses the current token as an infix operator between `left` and the expression after it,
+	// building an *ast.InfixExpression like those for `+` and `<`
+
+	return p.parseInfixExpression(left)
+}
+
+func (p *Parser) AddError(tok token.Token, msg string) {
+	// Records an error about the given token; a statement that adds errors is discarded and the
+	// parser resynchronizes as it does for built-in statements
+
+	p.addError(tok, "", msg)
+}
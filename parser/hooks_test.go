@@ -0,0 +1,257 @@
+// parser/hooks_test.go
+
+package parser
+
+import (
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/token"
+	"testing"
+)
+
+const (
+	UNLESS = "UNLESS"
+	MOD    = "MOD"
+	NOT    = "NOT"
+)
+
+func init() {
+	token.RegisterKeyword("unless", UNLESS)
+
+	// Operators are registered once for the whole package, like keywords
+	token.RegisterKeyword("mod", MOD)
+	token.RegisterKeyword("not", NOT)
+	RegisterInfixOperator(MOD, PRODUCT, (*Parser).ParseInfixExpression)
+	RegisterPrefixOperator(NOT, (*Parser).ParsePrefixExpression)
+}
+
+func registerUnless(p *Parser) {
+	// Adds an `unless <expression>;` statement for testing; there is no dedicated AST node for it, so
+	// it is represented as an expression statement holding the UNLESS token
+
+	p.RegisterStatement(UNLESS, func() ast.Statement {
+		stmt := &ast.ExpressionStatement{Token: p.CurToken()}
+
+		if p.PeekToken().Type == token.SEMICOLON {
+			p.AddError(p.PeekToken(), "unless needs a condition")
+			return nil
+		}
+
+		p.NextToken()
+		stmt.Expression = p.ParseExpression(LOWEST)
+
+		if !p.ExpectPeek(token.SEMICOLON) {
+			return nil
+		}
+
+		return stmt
+	})
+}
+
+func TestRegisterStatement(t *testing.T) {
+	// A registered keyword is parsed by its statement function
+
+	p := New(lexer.New("let x = 1; unless x < 2; x;"))
+	registerUnless(p)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 3 {
+		t.Fatalf("program.Statements does not contain 3 statements. got=%d",
+			len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[1].(*ast.ExpressionStatement)
+	if !ok || stmt.Token.Type != UNLESS {
+		t.Fatalf("program.Statements[1] is not an unless statement. got=%T (%+v)",
+			program.Statements[1], program.Statements[1])
+	}
+
+	if stmt.Expression.String() != "(x < 2)" {
+		t.Errorf("wrong condition. got=%q", stmt.Expression.String())
+	}
+}
+
+func TestRegisterStatementErrors(t *testing.T) {
+	// Errors from registered statements are reported and the parser resynchronizes at registered
+	// keywords
+
+	p := New(lexer.New("let x = ; unless ; unless x; x"))
+	registerUnless(p)
+
+	program := p.ParseProgram()
+
+	expected := []string{
+		"1:9: no prefix parse function for ; found",
+		"1:18: unless needs a condition",
+	}
+
+	if len(p.Errors()) != len(expected) {
+		t.Fatalf("wrong number of errors. expected=%d, got=%d (%v)", len(expected),
+			len(p.Errors()), p.Errors())
+	}
+
+	for i, err := range p.Errors() {
+		if err.Error() != expected[i] {
+			t.Errorf("errors[%d] wrong. expected=%q, got=%q", i, expected[i], err.Error())
+		}
+	}
+
+	if len(program.Statements) != 2 {
+		t.Errorf("program.Statements does not contain 2 statements. got=%d",
+			len(program.Statements))
+	}
+}
+
+func TestUnregisteredKeywordIsExpression(t *testing.T) {
+	// Without a statement function, a registered keyword has no meaning to the parser
+
+	p := New(lexer.New("unless x;"))
+	p.ParseProgram()
+
+	if len(p.Errors()) != 1 || p.Errors()[0].Message != "no prefix parse function for UNLESS found" {
+		t.Errorf("expected a missing prefix error. got=%v", p.Errors())
+	}
+}
+
+func TestRegisterOperators(t *testing.T) {
+	// Registered operators are parsed with their precedence relative to the built-in ones
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a + b mod c", "(a + (b mod c))"},
+		{"a mod b * c", "((a mod b) * c)"},
+		{"not a == b", "((nota) == b)"},
+		{"-a mod not b", "((-a) mod (notb))"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("program wrong for %q. expected=%q, got=%q", tt.input, tt.expected, actual)
+		}
+	}
+
+	if precedence := OperatorPrecedence(MOD); precedence != PRODUCT {
+		t.Errorf("OperatorPrecedence(MOD) wrong. expected=%d, got=%d", PRODUCT, precedence)
+	}
+}
+
+func TestRegisterOperatorsLater(t *testing.T) {
+	// Registering an operator again only affects parsers created afterwards
+
+	input := "a + b mod c"
+
+	before := New(lexer.New(input))
+
+	RegisterInfixOperator(MOD, SUM, (*Parser).ParseInfixExpression)
+	defer RegisterInfixOperator(MOD, PRODUCT, (*Parser).ParseInfixExpression)
+
+	after := New(lexer.New(input))
+
+	tests := []struct {
+		p        *Parser
+		expected string
+	}{
+		{before, "(a + (b mod c))"},
+		{after, "((a + b) mod c)"},
+	}
+
+	for _, tt := range tests {
+		program := tt.p.ParseProgram()
+		checkParserErrors(t, tt.p)
+
+		if actual := program.String(); actual != tt.expected {
+			t.Errorf("program wrong. expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	if precedence := OperatorPrecedence(MOD); precedence != SUM {
+		t.Errorf("OperatorPrecedence(MOD) wrong. expected=%d, got=%d", SUM, precedence)
+	}
+}
+
+func TestRegisterOperatorsLaterComparison(t *testing.T) {
+	// A parser created before an operator becomes a comparison doesn't treat it as one when checking
+	// for chained comparisons
+
+	p := New(lexer.New("a mod b == c"))
+
+	RegisterInfixOperator(MOD, EQUALS, (*Parser).ParseInfixExpression)
+	defer RegisterInfixOperator(MOD, PRODUCT, (*Parser).ParseInfixExpression)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if actual := program.String(); actual != "((a mod b) == c)" {
+		t.Errorf("program wrong. expected=%q, got=%q", "((a mod b) == c)", actual)
+	}
+}
+
+func TestPeekTokenN(t *testing.T) {
+	// Looking ahead from outside the package never panics; out-of-range distances give an ILLEGAL
+	// token
+
+	p := New(lexer.New("a + b;"))
+
+	tests := []struct {
+		n        int
+		expected token.Token
+	}{
+		{1, token.Token{Type: token.PLUS, Literal: "+"}},
+		{3, token.Token{Type: token.SEMICOLON, Literal: ";"}},
+		{MAX_LOOKAHEAD, token.Token{Type: token.EOF}},
+		{0, token.Token{Type: token.ILLEGAL}},
+		{-1, token.Token{Type: token.ILLEGAL}},
+		{MAX_LOOKAHEAD + 1, token.Token{Type: token.ILLEGAL}},
+	}
+
+	for _, tt := range tests {
+		tok := p.PeekTokenN(tt.n)
+
+		if tok.Type != tt.expected.Type || tok.Literal != tt.expected.Literal {
+			t.Errorf("PeekTokenN(%d) wrong. expected=%s %q, got=%s %q", tt.n, tt.expected.Type,
+				tt.expected.Literal, tok.Type, tok.Literal)
+		}
+	}
+
+	if p.CurToken().Literal != "a" {
+		t.Errorf("PeekTokenN advanced the parser. got=%q", p.CurToken().Literal)
+	}
+}
+
+func TestReset(t *testing.T) {
+	// A reset parser drops the errors of its previous input but keeps its mode and registered
+	// statements
+
+	l := lexer.New("let = 1; // comment")
+	p := NewWithMode(l, ParseComments)
+	registerUnless(p)
+
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Fatalf("expected errors for the first input")
+	}
+
+	l.Reset("unless x; // comment")
+	p.Reset(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 || program.String() != "x" {
+		t.Fatalf("program wrong after Reset. got=%q", program.String())
+	}
+
+	c := program.Comments[program.Statements[0]]
+	if c == nil || len(c.Trailing) != 1 || c.Trailing[0].Text != "// comment" {
+		t.Errorf("comments wrong after Reset. got=%+v", c)
+	}
+}
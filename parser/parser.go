@@ -7,14 +7,17 @@ import (
 	"monkey/ast"
 	"monkey/lexer"
 	"monkey/token"
+	"path"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 const (
 	// Operator precedences
 
 	// The iota keyword gives the following constants incrementing numbers as values; The blank
-	// identifier _ takes the zero value and the following constants get assigned the values 1 to 7
+	// identifier _ takes the zero value and the following constants get assigned the values 1 to 8
 	_ int = iota
 	LOWEST
 	EQUALS      // ==
@@ -23,10 +26,12 @@ const (
 	PRODUCT     // *
 	PREFIX      // -x or !x
 	CALL        // myFunction(x)
+	INDEX       // array[index] or array[start:end]
 )
 
 var precedences = map[token.TokenType]int{
-	// Maps the tokens to their respective precedences
+	// Maps the tokens to their respective precedences; never modified, since operators added with
+	// RegisterInfixOperator keep their precedences in infixOperators
 
 	token.EQ:       EQUALS,
 	token.NOT_EQ:   EQUALS,
@@ -36,25 +41,80 @@ var precedences = map[token.TokenType]int{
 	token.MINUS:    SUM,
 	token.SLASH:    PRODUCT,
 	token.ASTERISK: PRODUCT,
+	token.LBRACKET: INDEX,
 }
 
+// How many tokens past curToken the parser can look ahead, counting peekToken; see peekN
+const MAX_LOOKAHEAD = 4
+
+// Default limit on how deeply expressions and blocks can nest, which keeps pathological input like
+// thousands of `-` in a row from exhausting the stack; see SetMaxDepth
+const MAX_DEPTH = 1000
+
+// Flags that control optional parser behavior, combined with bitwise OR
+type Mode uint
+
+const (
+	// Attach comments to the statements they belong to in Program.Comments instead of discarding
+	// them
+	ParseComments Mode = 1 << iota
+
+	// Treat line breaks like semicolons wherever a statement could end, so `let x = a` followed by
+	// `-b` on the next line is two statements instead of `let x = a - b`; statements on the same line
+	// must then be separated by semicolons
+	InsertSemicolons
+)
+
 type Parser struct {
-	// The parser implementation
+	// The parser implementation; a Parser must only be used by one goroutine at a time, but separate
+	// parsers can run concurrently
 
-	l *lexer.Lexer
+	l    *lexer.Lexer
+	mode Mode
 
-	// Slice of strings to hold error messages
-	errors []string
+	// Comments read from the lexer that haven't been attached to a statement yet, and where attached
+	// comments go; commentMap is nil unless comments are being kept
+	comments   []*ast.Comment
+	commentMap ast.CommentMap
+
+	// Slice of errors along with their positions in the input
+	errors []ParserError
 
 	// These act like the two pointers that the lexer has, but instead of pointing to chars in the
 	// input, they point to tokens
 	curToken  token.Token
 	peekToken token.Token
 
+	// Tokens after peekToken that peekN has already read, as a ring buffer starting at aheadStart
+	ahead      [MAX_LOOKAHEAD - 1]token.Token
+	aheadStart int
+	aheadLen   int
+
 	// Used to check if the appropriate map (prefix or infix) has a parsing function associated with
 	// curToken.Type
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
+
+	// Precedences of the operators registered when the parser was created, which replace the
+	// built-in ones; nil if there were none
+	operatorPrecedences map[token.TokenType]int
+
+	// Parsing functions for statements added with RegisterStatement
+	statementParseFns map[token.TokenType]statementParseFn
+
+	// How deeply the expressions and blocks being parsed are nested, and the limit on it; tooDeep is
+	// set once the limit has been exceeded and the rest of the input skipped
+	depth    int
+	maxDepth int
+	tooDeep  bool
+
+	// The expression most recently parsed between parentheses, which can be compared again since the
+	// grouping shows the comparison of its result is intended
+	grouped ast.Expression
+
+	// The depth of the expression of the let, return, or expression statement being parsed, which
+	// a line break can end in InsertSemicolons mode; 0 when not in that mode
+	statementDepth int
 }
 
 type (
@@ -67,14 +127,26 @@ type (
 func New(l *lexer.Lexer) *Parser {
 	// Creates a new parser
 
-	p := &Parser{l: l, errors: []string{}}
+	return NewWithMode(l, 0)
+}
+
+func NewWithMode(l *lexer.Lexer, mode Mode) *Parser {
+	// Creates a new parser with optional behavior enabled
+
+	p := &Parser{l: l, mode: mode, errors: []ParserError{}, maxDepth: MAX_DEPTH}
 
 	// Initialize the prefix parse function map and register a parsing function
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.CHAR, p.parseCharLiteral)
+	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.RAW_STRING, p.parseStringLiteral)
+	p.registerPrefix(token.STRING_HEAD, p.parseInterpolatedString)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
+	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 
 	// Initialize the infix parse function map and register a parsing function
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -86,6 +158,25 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+
+	// Operators added with RegisterPrefixOperator and RegisterInfixOperator come last, so they
+	// replace the built-in ones
+	operatorsMu.RLock()
+	for t, fn := range prefixOperators {
+		p.registerPrefix(t, func() ast.Expression { return fn(p) })
+	}
+	for t, op := range infixOperators {
+		p.registerInfix(t, func(left ast.Expression) ast.Expression { return op.fn(p, left) })
+
+		if p.operatorPrecedences == nil {
+			p.operatorPrecedences = make(map[token.TokenType]int, len(infixOperators))
+		}
+		p.operatorPrecedences[t] = op.precedence
+	}
+	operatorsMu.RUnlock()
+
+	p.statementParseFns = make(map[token.TokenType]statementParseFn)
 
 	// Read two tokens, so curToken and peekToken are both set
 	p.nextToken()
@@ -94,24 +185,153 @@ func New(l *lexer.Lexer) *Parser {
 	return p
 }
 
-func (p *Parser) Errors() []string {
+func (p *Parser) Reset(l *lexer.Lexer) {
+	// Makes the parser start over on the tokens of `l`, keeping its mode, depth limit, and parse
+	// functions, including registered ones; errors from the previous input are dropped
+
+	p.l = l
+	p.errors = []ParserError{}
+	p.comments = nil
+	p.commentMap = nil
+	p.depth = 0
+	p.tooDeep = false
+	p.statementDepth = 0
+	p.grouped = nil
+	p.curToken = token.Token{}
+	p.peekToken = token.Token{}
+	p.aheadLen = 0
+
+	// Read two tokens, so curToken and peekToken are both set
+	p.nextToken()
+	p.nextToken()
+}
+
+func (p *Parser) SetMaxDepth(depth int) {
+	// Limits how deeply expressions and blocks can nest; input nested deeper is reported as an error
+	// and the rest of it is skipped, and a depth of 0 or less removes the limit
+
+	p.maxDepth = depth
+}
+
+func (p *Parser) Errors() []ParserError {
 	// Returns parser errors to check if any were encountered
 
 	return p.errors
 }
 
+func (p *Parser) Incomplete() bool {
+	// Reports whether the input ended in the middle of a statement, block, or string, i.e. every
+	// error is about the end of the input, so reading more input could make the errors go away; the
+	// program returned by ParseProgram is still the best that could be made of the input so far
+
+	if len(p.errors) == 0 {
+		return false
+	}
+
+	for _, err := range p.errors {
+		if err.Token.Type == token.EOF {
+			continue
+		}
+		if e, ok := p.l.ErrorAt(err.Token); !ok || e.Kind != lexer.UNTERMINATED_STRING {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *Parser) ErrorStrings() []string {
+	// Returns parser errors as plain messages without positions
+
+	messages := make([]string, len(p.errors))
+
+	for i, err := range p.errors {
+		messages[i] = err.Message
+	}
+
+	return messages
+}
+
+func (p *Parser) addError(tok token.Token, expected token.TokenType, msg string) {
+	// Records an error about the given token
+
+	// Past the depth limit the rest of the input is skipped, so later errors would only be about its
+	// missing end
+	if p.tooDeep {
+		return
+	}
+
+	// The lexer knows better than the parser what is wrong with an illegal token
+	if e, ok := p.l.ErrorAt(tok); ok {
+		msg = e.Message
+	}
+
+	p.errors = append(p.errors, ParserError{
+		Message:  msg,
+		Token:    tok,
+		Pos:      tok.Pos,
+		Expected: expected,
+		Got:      tok.Type,
+	})
+}
+
 func (p *Parser) peekError(t token.TokenType) {
 	// Adds a new error to the parser when the next token is not as expected
 
 	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.addError(p.peekToken, t, msg)
 }
 
 func (p *Parser) nextToken() {
 	// Advances curToken and peekToken
 
 	p.curToken = p.peekToken
-	p.peekToken = p.l.NextToken()
+
+	if p.aheadLen > 0 {
+		p.peekToken = p.ahead[p.aheadStart]
+		p.aheadStart = (p.aheadStart + 1) % len(p.ahead)
+		p.aheadLen--
+		return
+	}
+
+	p.peekToken = p.readToken()
+}
+
+func (p *Parser) readToken() token.Token {
+	// Returns the next token from the lexer, keeping any comments before it if they're wanted
+
+	tok := p.l.NextToken()
+
+	// Comments aren't part of the grammar, so they never become curToken or peekToken
+	for tok.Type == token.COMMENT {
+		if p.mode&ParseComments != 0 {
+			p.comments = append(p.comments, &ast.Comment{Token: tok, Text: tok.Literal})
+		}
+		tok = p.l.NextToken()
+	}
+
+	return tok
+}
+
+func (p *Parser) peekN(n int) token.Token {
+	// Returns the token `n` places after curToken without advancing, so peekN(1) is peekToken;
+	// constructs that can't be told apart by peekToken alone look further ahead with this, up to
+	// MAX_LOOKAHEAD tokens
+
+	if n < 1 || n > MAX_LOOKAHEAD {
+		panic(fmt.Sprintf("parser: cannot look %d tokens ahead", n))
+	}
+
+	if n == 1 {
+		return p.peekToken
+	}
+
+	for p.aheadLen < n-1 {
+		p.ahead[(p.aheadStart+p.aheadLen)%len(p.ahead)] = p.readToken()
+		p.aheadLen++
+	}
+
+	return p.ahead[(p.aheadStart+n-2)%len(p.ahead)]
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -121,35 +341,159 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 
+	if p.mode&ParseComments != 0 {
+		program.Comments = ast.CommentMap{}
+		p.commentMap = program.Comments
+	}
+
 	for !p.curTokenIs(token.EOF) {
+		errorCount := len(p.errors)
+		start := p.curToken.Pos
+
 		stmt := p.parseStatement()
-		if stmt != nil {
+		if len(p.errors) == errorCount {
+			p.statementEnd()
+		}
+
+		// A statement that produced errors is discarded, and the tokens up to the next
+		// synchronization point are skipped so that one bad token doesn't derail the statements that
+		// follow it
+		if len(p.errors) > errorCount {
+			p.synchronize()
+		} else if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
+			p.attachComments(stmt, start, p.curToken.Pos.Line)
 		}
+
 		p.nextToken()
 	}
 
+	// Whatever is left over comes after the last statement
+	if len(p.comments) > 0 && program.Comments != nil {
+		program.Comments[program] = &ast.NodeComments{Trailing: p.comments}
+		p.comments = nil
+	}
+
 	return program
 }
 
+func (p *Parser) attachComments(stmt ast.Statement, start token.Position, endLine int) {
+	// Attaches the pending comments that come before the statement as leading comments and those up
+	// to the end of its last line as trailing comments; later comments stay pending
+
+	if p.commentMap == nil || len(p.comments) == 0 {
+		return
+	}
+
+	comments := &ast.NodeComments{}
+	pending := []*ast.Comment{}
+
+	for _, c := range p.comments {
+		pos := c.Token.Pos
+
+		if pos.Line < start.Line || pos.Line == start.Line && pos.Column < start.Column {
+			comments.Leading = append(comments.Leading, c)
+		} else if pos.Line <= endLine {
+			comments.Trailing = append(comments.Trailing, c)
+		} else {
+			pending = append(pending, c)
+		}
+	}
+
+	p.comments = pending
+
+	if len(comments.Leading) > 0 || len(comments.Trailing) > 0 {
+		p.commentMap[stmt] = comments
+	}
+}
+
+func (p *Parser) synchronize() {
+	// Advances until curToken is the semicolon ending the broken statement, or until peekToken is a
+	// keyword that can only start a new statement, including registered ones; ParseProgram then
+	// moves past curToken as usual
+
+	for !p.curTokenIs(token.SEMICOLON) && !p.curTokenIs(token.EOF) {
+		switch p.peekToken.Type {
+		case token.LET, token.CONST, token.RETURN, token.IMPORT, token.FOR:
+			return
+		}
+
+		if _, ok := p.statementParseFns[p.peekToken.Type]; ok {
+			return
+		}
+
+		p.nextToken()
+	}
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	// Parses a statement based on its corresponding token
 
-	// The only two pure statement types in monkey are `let` and `return` statements, so if they
-	// aren't encountered, the statement must be an expression
+	// The only pure statement types in monkey are `let`, `const`, `return`, `import`, and `for`
+	// statements, so if they aren't encountered, the statement must be an expression
 	switch p.curToken.Type {
-	case token.LET:
+	case token.LET, token.CONST:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.FOR:
+		return p.parseForStatement()
 	default:
+		if fn, ok := p.statementParseFns[p.curToken.Type]; ok {
+			return fn()
+		}
 		return p.parseExpressionStatement()
 	}
 }
 
+func (p *Parser) statementEnd() {
+	// Checks that the statement just parsed is followed by a semicolon, a line break, the end of its
+	// block, or the end of the input; only enforced in InsertSemicolons mode
+
+	if p.mode&InsertSemicolons == 0 || p.curTokenIs(token.SEMICOLON) {
+		return
+	}
+
+	if p.peekTokenIs(token.EOF) || p.peekTokenIs(token.RBRACE) || p.lineBreakAhead() {
+		return
+	}
+
+	msg := fmt.Sprintf("expected ; or a line break after the statement, got %s instead",
+		p.peekToken.Type)
+	p.addError(p.peekToken, token.SEMICOLON, msg)
+}
+
+func (p *Parser) lineBreakAhead() bool {
+	// Checks if peekToken starts on a later line than curToken ends on; strings can span lines
+
+	return p.peekToken.Pos.Line > p.curToken.Pos.Line+strings.Count(p.curToken.Literal, "\n")
+}
+
+func (p *Parser) parseStatementExpression() ast.Expression {
+	// Parses the expression of a let, return, or expression statement; in InsertSemicolons mode, a
+	// line break ends it wherever it could end, but not inside brackets or match arms
+
+	if p.mode&InsertSemicolons == 0 {
+		return p.parseExpression(LOWEST)
+	}
+
+	outer := p.statementDepth
+	p.statementDepth = p.depth + 1
+	defer func() { p.statementDepth = outer }()
+
+	return p.parseExpression(LOWEST)
+}
+
 func (p *Parser) parseExpression(precedence int) ast.Expression {
 	// Parses an expression based on its operator precedence
 
+	if !p.enter() {
+		return nil
+	}
+	defer p.leave()
+
 	// Check if there is a parsing function associated with the current token type in the prefix
 	// position
 	prefix := p.prefixParseFns[p.curToken.Type]
@@ -164,6 +508,10 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	// Tries to find infix expressions until encountering a semicolon or a token with a lower
 	// precedence
 	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
+		if p.depth == p.statementDepth && p.lineBreakAhead() {
+			return leftExp
+		}
+
 		infix := p.infixParseFns[p.peekToken.Type]
 
 		if infix == nil {
@@ -178,6 +526,38 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	return leftExp
 }
 
+func (p *Parser) enter() bool {
+	// Goes one level deeper into nested expressions or blocks; past the depth limit, records an
+	// error and skips to the end of the input so the enclosing levels unwind quickly, and returns
+	// false
+
+	if p.tooDeep {
+		return false
+	}
+
+	if p.maxDepth > 0 && p.depth >= p.maxDepth {
+		msg := fmt.Sprintf("nesting exceeds the maximum depth of %d", p.maxDepth)
+		p.addError(p.curToken, "", msg)
+		p.tooDeep = true
+
+		for !p.curTokenIs(token.EOF) {
+			p.nextToken()
+		}
+
+		return false
+	}
+
+	p.depth++
+
+	return true
+}
+
+func (p *Parser) leave() {
+	// Goes back up a level after enter
+
+	p.depth--
+}
+
 func (p *Parser) parseIdentifier() ast.Expression {
 	// Returns an identifier with the current token and the current token literal
 
@@ -185,8 +565,10 @@ func (p *Parser) parseIdentifier() ast.Expression {
 }
 
 func (p *Parser) parseLetStatement() *ast.LetStatement {
-	// Constructs an *ast.LetStatement node with a LET token
+	// Constructs an *ast.LetStatement node with a LET or CONST token
 	// let <identifer> = <expression>;
+	// let <identifer>: <type> = <expression>;
+	// const <identifer> = <expression>;
 
 	stmt := &ast.LetStatement{Token: p.curToken}
 
@@ -197,15 +579,28 @@ func (p *Parser) parseLetStatement() *ast.LetStatement {
 
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
+	// Check for an optional type annotation
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.Type = &ast.TypeName{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
 	// Ensure the assignment operator exists
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
 
-	// TODO: 06/19/24 - For now, we're skipping the expressions until we encounter a semicolon
+	p.nextToken()
+
+	stmt.Value = p.parseStatementExpression()
 
-	// Ensure the line ends
-	for !p.curTokenIs(token.SEMICOLON) {
+	// Check for an optional semicolon
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -220,22 +615,202 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 	p.nextToken()
 
-	// TODO: 06/22/24 - For now, we're skipping the expressions until we encounter a semicolon
+	stmt.ReturnValue = p.parseStatementExpression()
+
+	// Check for an optional semicolon
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	// Constructs an *ast.ImportStatement node with an IMPORT token; the module is bound to the last
+	// element of its path, so that element has to be a valid identifier
+	// import "<path>";
+
+	stmt := &ast.ImportStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+
+	stmt.Path = p.parseText()
+	if stmt.Path == nil {
+		return nil
+	}
+
+	name := path.Base(stmt.Path.Value)
+	if !isIdentifier(name) || token.LookupIdent(name) != token.IDENT {
+		msg := fmt.Sprintf("cannot import %q: the last element of the path must be an identifier",
+			stmt.Path.Value)
+		p.addError(p.curToken, "", msg)
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: name}
+
+	// Check for an optional semicolon
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func isIdentifier(s string) bool {
+	// Checks if `s` is non-empty and consists only of chars the lexer accepts in identifiers
+
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if !('a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_') {
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+func (p *Parser) parseForStatement() *ast.ForStatement {
+	// Constructs an *ast.ForStatement node with a FOR token
+	// for (<value> in <expression>) { <statements> }
+	// for (<key>, <value> in <expression>) { <statements> }
+
+	stmt := &ast.ForStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+
+	stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	// With two variables, the first one is the key
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.Key = stmt.Value
+		stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
 
-	for !p.curTokenIs(token.SEMICOLON) {
+	stmt.Body = p.parseBlockStatement()
+	if stmt.Body == nil {
+		return nil
+	}
+
+	// Check for an optional semicolon
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
 	return stmt
 }
 
+func (p *Parser) parseBlockStatement() *ast.BlockStatement {
+	// Constructs an *ast.BlockStatement node with an LBRACE token, parsing statements until the
+	// closing brace; broken statements are skipped the same way ParseProgram skips them
+	// { <statements> }
+
+	if !p.enter() {
+		return nil
+	}
+	defer p.leave()
+
+	block := &ast.BlockStatement{Token: p.curToken}
+	block.Statements = []ast.Statement{}
+
+	// Comments before the block belong to the statement containing it, while those the lookahead
+	// has read after the opening brace belong to the block
+	var outer, inner []*ast.Comment
+	for _, c := range p.comments {
+		if c.Token.Pos.Offset < block.Token.Pos.Offset {
+			outer = append(outer, c)
+		} else {
+			inner = append(inner, c)
+		}
+	}
+	p.comments = inner
+
+	p.nextToken()
+
+	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		errorCount := len(p.errors)
+		start := p.curToken.Pos
+
+		stmt := p.parseStatement()
+		if len(p.errors) == errorCount {
+			p.statementEnd()
+		}
+
+		if len(p.errors) > errorCount {
+			p.synchronize()
+		} else if stmt != nil {
+			block.Statements = append(block.Statements, stmt)
+			p.attachComments(stmt, start, p.curToken.Pos.Line)
+		}
+
+		p.nextToken()
+	}
+
+	if !p.curTokenIs(token.RBRACE) {
+		msg := fmt.Sprintf("expected %s to close the block opened at %s, got %s instead",
+			token.RBRACE, block.Token.Pos, p.curToken.Type)
+		p.addError(p.curToken, token.RBRACE, msg)
+		return nil
+	}
+
+	block.Rbrace = p.curToken
+
+	// Comments left over from inside the block come after its last statement; the lookahead may
+	// already have read comments following the closing brace, which stay pending
+	var inside []*ast.Comment
+	for _, c := range p.comments {
+		if c.Token.Pos.Offset < block.Rbrace.Pos.Offset {
+			inside = append(inside, c)
+		} else {
+			outer = append(outer, c)
+		}
+	}
+
+	if len(inside) > 0 && p.commentMap != nil {
+		p.commentMap[block] = &ast.NodeComments{Trailing: inside}
+	}
+
+	p.comments = outer
+
+	return block
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	// Constructs an *ast.ExpressionStatement node with an expression statement
 
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 
 	// Parse the expression starting with the lowest operator precedence
-	stmt.Expression = p.parseExpression(LOWEST)
+	stmt.Expression = p.parseStatementExpression()
 
 	// Check for an optional semicolon
 	if p.peekTokenIs(token.SEMICOLON) {
@@ -255,7 +830,7 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(p.curToken, "", msg)
 		return nil
 	}
 
@@ -264,6 +839,89 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return lit
 }
 
+func (p *Parser) parseCharLiteral() ast.Expression {
+	// Constructs an *ast.CharLiteral node with a character literal, interpreting escapes the same
+	// way Go does
+
+	s, err := strconv.Unquote("'" + p.curToken.Literal + "'")
+
+	if err != nil || s == "" {
+		msg := fmt.Sprintf("could not parse '%s' as character", p.curToken.Literal)
+		p.addError(p.curToken, "", msg)
+		return nil
+	}
+
+	value, _ := utf8.DecodeRuneInString(s)
+
+	return &ast.CharLiteral{Token: p.curToken, Value: value}
+}
+
+func (p *Parser) parseStringLiteral() ast.Expression {
+	// Constructs an *ast.StringLiteral node with a string literal; raw strings are kept as written
+
+	if p.curTokenIs(token.RAW_STRING) {
+		return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	// Returned through a check so a failed parse is a nil interface rather than a nil *StringLiteral
+	if s := p.parseText(); s != nil {
+		return s
+	}
+
+	return nil
+}
+
+func (p *Parser) parseText() *ast.StringLiteral {
+	// Constructs an *ast.StringLiteral node with the current double-quoted string, or the text
+	// between the delimiters of an interpolated string, interpreting its escape sequences
+
+	value, err := lexer.Unescape(p.curToken.Literal)
+	if err != nil {
+		p.addError(p.curToken, "", err.Error())
+		return nil
+	}
+
+	return &ast.StringLiteral{Token: p.curToken, Value: value}
+}
+
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	// Constructs an *ast.InterpolatedString node with a STRING_HEAD token, alternating between the
+	// text of the string and the expressions embedded in it
+	// "<text>${<expression>}<text>${<expression>}<text>"
+
+	expression := &ast.InterpolatedString{Token: p.curToken}
+
+	for {
+		text := p.parseText()
+		if text == nil {
+			return nil
+		}
+
+		expression.Parts = append(expression.Parts, text)
+
+		if p.curTokenIs(token.STRING_TAIL) {
+			return expression
+		}
+
+		p.nextToken()
+
+		if p.curTokenIs(token.STRING_MIDDLE) || p.curTokenIs(token.STRING_TAIL) {
+			p.addError(p.curToken, "", "missing expression in string interpolation")
+			return nil
+		}
+
+		expression.Parts = append(expression.Parts, p.parseExpression(LOWEST))
+
+		// The expression has to be followed by more of the string
+		if !p.peekTokenIs(token.STRING_MIDDLE) && !p.peekTokenIs(token.STRING_TAIL) {
+			p.peekError(token.STRING_TAIL)
+			return nil
+		}
+
+		p.nextToken()
+	}
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	// Constructs an *ast.PrefixExpression node with a prefix expression
 
@@ -301,6 +959,139 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 
 	expression.Right = p.parseExpression(precedence)
 
+	// `a < b < c` would compare the bool `a < b` with c, which is almost never what was meant, so
+	// comparisons of the same precedence can't follow each other unless the first is parenthesized
+	chained, ok := left.(*ast.InfixExpression)
+	if ok && left != p.grouped && expression.Right != nil && (precedence == EQUALS || precedence == LESSGREATER) &&
+		p.precedence(chained.Token.Type) == precedence {
+		msg := fmt.Sprintf("cannot chain comparisons: %s compares the result of %s with %s",
+			expression, chained, expression.Right)
+		p.addError(expression.Token, "", msg)
+		return nil
+	}
+
+	return expression
+}
+
+func (p *Parser) parseGroupedExpression() ast.Expression {
+	// Parses an expression between parentheses, which override the precedence of the operators
+	// around it; the parentheses don't get a node of their own
+	// (<expression>)
+
+	p.nextToken()
+
+	expression := p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	p.grouped = expression
+
+	return expression
+}
+
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	// Constructs an *ast.IndexExpression node, or an *ast.SliceExpression node if there is a colon
+	// inside the brackets; either side of the colon may be left out
+	// <expression>[<index>] or <expression>[<low>:<high>]
+
+	tok := p.curToken
+
+	p.nextToken()
+
+	var low ast.Expression
+
+	if !p.curTokenIs(token.COLON) {
+		low = p.parseExpression(LOWEST)
+
+		if !p.peekTokenIs(token.COLON) {
+			if !p.expectPeek(token.RBRACKET) {
+				return nil
+			}
+
+			return &ast.IndexExpression{Token: tok, Left: left, Index: low, Rbracket: p.curToken}
+		}
+
+		p.nextToken()
+	}
+
+	slice := &ast.SliceExpression{Token: tok, Left: left, Low: low}
+
+	// The current token is the colon at this point
+	if !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		slice.High = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	slice.Rbracket = p.curToken
+
+	return slice
+}
+
+func (p *Parser) parseMatchExpression() ast.Expression {
+	// Constructs an *ast.MatchExpression node with a MATCH token
+	// match (<subject>) { <pattern> => <value>, ..., _ => <default> }
+
+	expression := &ast.MatchExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	// Arms are separated by commas, and a trailing comma before the closing brace is allowed
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		// The wildcard `_` marks the default arm, which has to come last
+		isDefault := p.curTokenIs(token.IDENT) && p.curToken.Literal == "_"
+
+		if expression.Default != nil {
+			p.addError(p.curToken, "", "match arms after the default arm can never match")
+			return nil
+		}
+
+		var pattern ast.Expression
+		if !isDefault {
+			pattern = p.parseExpression(LOWEST)
+		}
+
+		if !p.expectPeek(token.FAT_ARROW) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+
+		if isDefault {
+			expression.Default = value
+		} else {
+			expression.Arms = append(expression.Arms, &ast.MatchArm{Pattern: pattern, Value: value})
+		}
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	p.nextToken()
+	expression.Rbrace = p.curToken
+
 	return expression
 }
 
@@ -345,25 +1136,51 @@ func (p *Parser) noPrefixParseFnError(t token.TokenType) {
 	// Returns an error if an invalid prefix parse operator is found
 
 	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.addError(p.curToken, "", msg)
 }
 
-func (p *Parser) peekPrecedence() int {
-	// Returns the precedence of the next token; if a match isn't found, returns lowest
+func OperatorPrecedence(t token.TokenType) int {
+	// Returns the precedence a parser created now would give an infix operator token, including
+	// those added with RegisterInfixOperator; if a match isn't found, returns lowest
+
+	operatorsMu.RLock()
+	op, ok := infixOperators[t]
+	operatorsMu.RUnlock()
 
-	if p, ok := precedences[p.peekToken.Type]; ok {
+	if ok {
+		return op.precedence
+	}
+
+	if p, ok := precedences[t]; ok {
 		return p
 	}
 
 	return LOWEST
 }
 
-func (p *Parser) curPrecedence() int {
-	// Returns the precedence of the current token; if a match isn't found, returns lowest
+func (p *Parser) precedence(t token.TokenType) int {
+	// Returns the precedence this parser gives an infix operator token; if a match isn't found,
+	// returns lowest
 
-	if p, ok := precedences[p.curToken.Type]; ok {
-		return p
+	if precedence, ok := p.operatorPrecedences[t]; ok {
+		return precedence
+	}
+
+	if precedence, ok := precedences[t]; ok {
+		return precedence
 	}
 
 	return LOWEST
 }
+
+func (p *Parser) peekPrecedence() int {
+	// Returns the precedence of the next token; if a match isn't found, returns lowest
+
+	return p.precedence(p.peekToken.Type)
+}
+
+func (p *Parser) curPrecedence() int {
+	// Returns the precedence of the current token; if a match isn't found, returns lowest
+
+	return p.precedence(p.curToken.Type)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"monkey/ast"
 	"monkey/lexer"
+	"strings"
 	"testing"
 )
 
@@ -38,10 +39,11 @@ func TestLetStatements(t *testing.T) {
 	// Expected parser output
 	tests := []struct {
 		expectedIdentifier string
+		expectedValue      int64
 	}{
-		{"x"},
-		{"y"},
-		{"foobar"},
+		{"x", 5},
+		{"y", 10},
+		{"foobar", 838383},
 	}
 
 	for i, tt := range tests {
@@ -49,6 +51,10 @@ func TestLetStatements(t *testing.T) {
 		if !testLetStatement(t, stmt, tt.expectedIdentifier) {
 			return
 		}
+
+		if !testIntegerLiteral(t, stmt.(*ast.LetStatement).Value, tt.expectedValue) {
+			return
+		}
 	}
 }
 
@@ -72,7 +78,9 @@ func TestReturnStatements(t *testing.T) {
 			len(program.Statements))
 	}
 
-	for _, stmt := range program.Statements {
+	expectedValues := []int64{5, 10, 993322}
+
+	for i, stmt := range program.Statements {
 		returnStmt, ok := stmt.(*ast.ReturnStatement)
 		if !ok {
 			t.Errorf("stmt not *ast.ReturnStatement. got=%T", stmt)
@@ -81,6 +89,7 @@ func TestReturnStatements(t *testing.T) {
 		if returnStmt.TokenLiteral() != "return" {
 			t.Errorf("returnStmt.TokenLiteral not 'return', got %q", returnStmt.TokenLiteral())
 		}
+		testIntegerLiteral(t, returnStmt.ReturnValue, expectedValues[i])
 	}
 }
 
@@ -279,6 +288,22 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"-a * b",
 			"((-a) * b)",
 		},
+		{
+			"(a + b) * c",
+			"((a + b) * c)",
+		},
+		{
+			"a - (b - (c))",
+			"(a - (b - c))",
+		},
+		{
+			"-(a + b)[0]",
+			"(-((a + b)[0]))",
+		},
+		{
+			"(a < b) == (c != d)",
+			"((a < b) == (c != d))",
+		},
 		{
 			"!-a",
 			"(!(-a))",
@@ -323,6 +348,18 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"3 + 4 * 5 == 3 * 1 + 4 * 5",
 			"((3 + (4 * 5)) == ((3 * 1) + (4 * 5)))",
 		},
+		{
+			"a * b[2] + c",
+			"((a * (b[2])) + c)",
+		},
+		{
+			"-a[1:b + 1]",
+			"(-(a[1:(b + 1)]))",
+		},
+		{
+			"a[:][1:]",
+			"((a[:])[1:])",
+		},
 	}
 
 	for _, tt := range tests {
@@ -351,8 +388,8 @@ func checkParserErrors(t *testing.T, p *Parser) {
 
 	t.Errorf("parser has %d errors", len(errors))
 
-	for _, msg := range errors {
-		t.Errorf("parser error: %q", msg)
+	for _, err := range errors {
+		t.Errorf("parser error: %q", err.Error())
 	}
 
 	t.FailNow()
@@ -416,3 +453,628 @@ func testIntegerLiteral(t *testing.T, il ast.Expression, value int64) bool {
 
 	return true
 }
+
+func TestParseComments(t *testing.T) {
+	// Comments are dropped by default and attached to statements with ParseComments
+
+	input := `// leading
+let x = 5; // trailing
+x + // inside
+1;
+// dangling`
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if program.Comments != nil {
+		t.Errorf("expected no comments by default. got=%v", program.Comments)
+	}
+
+	p = NewWithMode(lexer.New(input), ParseComments)
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d",
+			len(program.Statements))
+	}
+
+	texts := func(comments []*ast.Comment) string {
+		var out []string
+		for _, c := range comments {
+			out = append(out, c.Text)
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := []struct {
+		node             ast.Node
+		expectedLeading  string
+		expectedTrailing string
+	}{
+		{program.Statements[0], "// leading", "// trailing"},
+		{program.Statements[1], "", "// inside"},
+		{program, "", "// dangling"},
+	}
+
+	for i, tt := range tests {
+		c := program.Comments[tt.node]
+		if c == nil {
+			t.Fatalf("tests[%d] - no comments attached", i)
+		}
+
+		if texts(c.Leading) != tt.expectedLeading || texts(c.Trailing) != tt.expectedTrailing {
+			t.Errorf("tests[%d] - comments wrong. expected=%q/%q, got=%q/%q", i,
+				tt.expectedLeading, tt.expectedTrailing, texts(c.Leading), texts(c.Trailing))
+		}
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	// Compares raw monkey input and expected parser output for match expressions
+
+	input := "match (x + 1) { 1 => a, 2 => b * c, _ => -d }"
+
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MatchExpression. got=%T", stmt.Expression)
+	}
+
+	if exp.Subject.String() != "(x + 1)" {
+		t.Errorf("exp.Subject wrong. got=%q", exp.Subject.String())
+	}
+
+	if len(exp.Arms) != 2 {
+		t.Fatalf("exp.Arms does not contain 2 arms. got=%d", len(exp.Arms))
+	}
+
+	if !testIntegerLiteral(t, exp.Arms[0].Pattern, 1) || !testIntegerLiteral(t, exp.Arms[1].Pattern, 2) {
+		return
+	}
+
+	if exp.Default == nil || exp.Default.String() != "(-d)" {
+		t.Errorf("exp.Default wrong. got=%v", exp.Default)
+	}
+
+	expected := "match ((x + 1)) { 1 => a, 2 => (b * c), _ => (-d) }"
+	if program.String() != expected {
+		t.Errorf("program.String() wrong. expected=%q, got=%q", expected, program.String())
+	}
+}
+
+func TestImportStatements(t *testing.T) {
+	// Compares raw monkey input and the expected path and namespace of import statements
+
+	tests := []struct {
+		input        string
+		expectedPath string
+		expectedName string
+	}{
+		{`import "math";`, "math", "math"},
+		{`import "lib/strings"`, "lib/strings", "strings"},
+		{`import "./lib/io/";`, "./lib/io/", "io"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ImportStatement. got=%T", program.Statements[0])
+		}
+
+		if stmt.Path.Value != tt.expectedPath {
+			t.Errorf("stmt.Path.Value wrong. expected=%q, got=%q", tt.expectedPath, stmt.Path.Value)
+		}
+
+		if stmt.Name.Value != tt.expectedName {
+			t.Errorf("stmt.Name.Value wrong. expected=%q, got=%q", tt.expectedName, stmt.Name.Value)
+		}
+	}
+}
+
+func TestImportStatementErrors(t *testing.T) {
+	// Checks that imports whose paths don't end in a usable namespace name are rejected
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import math;`, `expected next token to be STRING, got IDENT instead`},
+		{`import "";`, `cannot import "": the last element of the path must be an identifier`},
+		{`import "lib/v2";`, `cannot import "lib/v2": the last element of the path must be an identifier`},
+		{`import "lib/let";`, `cannot import "lib/let": the last element of the path must be an identifier`},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		errors := p.ErrorStrings()
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("errors wrong for %q. expected=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
+func TestForStatements(t *testing.T) {
+	// Compares raw monkey input and the expected variables, iterable, and body of for loops
+
+	tests := []struct {
+		input            string
+		expectedKey      string
+		expectedValue    string
+		expectedIterable string
+		expectedBody     []string
+	}{
+		{"for (x in xs) { x; }", "", "x", "xs", []string{"x"}},
+		{"for (k, v in a + b) { let y = v; k }", "k", "v", "(a + b)", []string{"let y = v;", "k"}},
+		{"for (x in xs) {};", "", "x", "xs", []string{}},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T", program.Statements[0])
+		}
+
+		key := ""
+		if stmt.Key != nil {
+			key = stmt.Key.Value
+		}
+
+		if key != tt.expectedKey || stmt.Value.Value != tt.expectedValue {
+			t.Errorf("loop variables wrong. expected=%q, %q, got=%q, %q", tt.expectedKey,
+				tt.expectedValue, key, stmt.Value.Value)
+		}
+
+		if stmt.Iterable.String() != tt.expectedIterable {
+			t.Errorf("stmt.Iterable wrong. expected=%q, got=%q", tt.expectedIterable,
+				stmt.Iterable.String())
+		}
+
+		if len(stmt.Body.Statements) != len(tt.expectedBody) {
+			t.Fatalf("stmt.Body.Statements wrong length. expected=%d, got=%d", len(tt.expectedBody),
+				len(stmt.Body.Statements))
+		}
+
+		for i, s := range stmt.Body.Statements {
+			if s.String() != tt.expectedBody[i] {
+				t.Errorf("stmt.Body.Statements[%d] wrong. expected=%q, got=%q", i, tt.expectedBody[i],
+					s.String())
+			}
+		}
+	}
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	// Compares let statements with and without type annotations against their expected output
+
+	tests := []struct {
+		input        string
+		expectedType string
+		expected     string
+	}{
+		{"let x: int = 5;", "int", "let x: int = 5;"},
+		{"let s : string = t", "string", "let s: string = t;"},
+		{"let y = 5;", "", "let y = 5;"},
+		{"const z: int = 5", "int", "const z: int = 5;"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.LetStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.LetStatement. got=%T", program.Statements[0])
+		}
+
+		typeName := ""
+		if stmt.Type != nil {
+			typeName = stmt.Type.Value
+		}
+
+		if typeName != tt.expectedType {
+			t.Errorf("stmt.Type wrong. expected=%q, got=%q", tt.expectedType, typeName)
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("program.String() wrong. expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestInterpolatedString(t *testing.T) {
+	// Compares raw monkey input and the expected parts of strings with embedded expressions
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`"sum is ${a + b}";`, []string{"sum is ", "(a + b)", ""}},
+		{`"${a}${b}"`, []string{"", "a", "", "b", ""}},
+		{`"${ "x${y}z" }!"`, []string{"", "x${y}z", "!"}},
+		{`"${match (x) { 1 => "a" }}"`, []string{"", "match (x) { 1 => a }", ""}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d", len(program.Statements))
+		}
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		str, ok := stmt.Expression.(*ast.InterpolatedString)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.InterpolatedString. got=%T", stmt.Expression)
+		}
+
+		if len(str.Parts) != len(tt.expected) {
+			t.Fatalf("str.Parts has wrong length for %q. expected=%d, got=%d", tt.input,
+				len(tt.expected), len(str.Parts))
+		}
+
+		for i, part := range str.Parts {
+			if part.String() != tt.expected[i] {
+				t.Errorf("str.Parts[%d] wrong. expected=%q, got=%q", i, tt.expected[i], part.String())
+			}
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	// Escape sequences in double-quoted strings and the text of interpolated strings are
+	// interpreted, while raw strings are kept as written
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`"say \"hi\"\n";`, []string{"say \"hi\"\n"}},
+		{"`say \\\"hi\\\"`;", []string{`say \"hi\"`}},
+		{`"\${x} = ${x}\t";`, []string{"${x} = ", "x", "\t"}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		parts := []ast.Expression{stmt.Expression}
+		if s, ok := stmt.Expression.(*ast.InterpolatedString); ok {
+			parts = s.Parts
+		}
+
+		if len(parts) != len(tt.expected) {
+			t.Fatalf("wrong number of parts for %s. expected=%d, got=%d", tt.input,
+				len(tt.expected), len(parts))
+		}
+
+		for i, part := range parts {
+			actual := part.String()
+			if s, ok := part.(*ast.StringLiteral); ok {
+				actual = s.Value
+			}
+
+			if actual != tt.expected[i] {
+				t.Errorf("parts[%d] wrong for %s. expected=%q, got=%q", i, tt.input, tt.expected[i],
+					actual)
+			}
+		}
+	}
+
+	p := New(lexer.New(`"a\qb";`))
+	p.ParseProgram()
+
+	expected := "unknown escape sequence \\q in string"
+	if errors := p.ErrorStrings(); len(errors) != 1 || errors[0] != expected {
+		t.Errorf("errors wrong. expected=%q, got=%q", expected, errors)
+	}
+}
+
+func TestCharLiteral(t *testing.T) {
+	// Compares character literals with the code points they hold, and checks that malformed ones are
+	// reported
+
+	tests := []struct {
+		input    string
+		expected rune
+	}{
+		{`'a';`, 'a'},
+		{`'\n';`, '\n'},
+		{`'\'';`, '\''},
+		{`'é';`, 'é'},
+		{`'\u00e9';`, 'é'},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+		char, ok := stmt.Expression.(*ast.CharLiteral)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.CharLiteral. got=%T", stmt.Expression)
+		}
+
+		if char.Value != tt.expected {
+			t.Errorf("char.Value wrong for %s. expected=%q, got=%q", tt.input, tt.expected, char.Value)
+		}
+	}
+
+	for _, input := range []string{`'';`, `'ab';`, `'\q';`} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+
+		expected := fmt.Sprintf("could not parse %s as character", input[:len(input)-1])
+
+		errors := p.ErrorStrings()
+		if len(errors) == 0 || errors[0] != expected {
+			t.Errorf("errors wrong for %s. expected=%q, got=%q", input, expected, errors)
+		}
+	}
+}
+
+func TestIncomplete(t *testing.T) {
+	// Checks that input is incomplete only when every error is about the input ending too early
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"let x = 1;", false},
+		{"let x = 1", false},
+		{"let x =", true},
+		{"let", true},
+		{"for (x in xs) {", true},
+		{"for (x in xs) { let y = ", true},
+		{"match (x) { 1 => a,", true},
+		{`let s = "abc`, true},
+		{"let s = `a\n", true},
+		{`let s = "a ${x} b`, true},
+		{`let s = "a ${x`, true},
+		{"let = 1; for (x in xs) {", false},
+		{"let x = ;", false},
+		{"'a", false},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+
+		if actual := p.Incomplete(); actual != tt.expected {
+			t.Errorf("Incomplete() wrong for %q. expected=%t, got=%t (%v)", tt.input, tt.expected,
+				actual, p.Errors())
+		}
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	// Checks that input nested past the depth limit is reported once instead of exhausting the stack
+
+	tests := []struct {
+		input    string
+		maxDepth int
+		expected string
+	}{
+		{strings.Repeat("-", 1_000_000) + "x", MAX_DEPTH,
+			"1:1001: nesting exceeds the maximum depth of 1000"},
+		{"let x = a[b[c[d]]];", 3, "1:15: nesting exceeds the maximum depth of 3"},
+		{"for (a in b) { for (c in d) { for (e in f) { e } } }", 2,
+			"1:41: nesting exceeds the maximum depth of 2"},
+		{"let x = !!!!!y; let z = 1;", 0, ""},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.SetMaxDepth(tt.maxDepth)
+		p.ParseProgram()
+
+		if tt.expected == "" {
+			checkParserErrors(t, p)
+			continue
+		}
+
+		if len(p.Errors()) != 1 || p.Errors()[0].Error() != tt.expected {
+			t.Errorf("errors wrong for %.20q. expected=%q, got=%v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestPeekN(t *testing.T) {
+	// Checks that looking ahead returns the tokens in order without advancing, skipping comments,
+	// and that advancing afterwards uses the tokens already read
+
+	p := NewWithMode(lexer.New("a + b // comment\n* c; d"), ParseComments)
+
+	expected := []string{"+", "b", "*", "c"}
+	for i, literal := range expected {
+		if tok := p.peekN(i + 1); tok.Literal != literal {
+			t.Errorf("peekN(%d) wrong. expected=%q, got=%q", i+1, literal, tok.Literal)
+		}
+	}
+
+	if p.curToken.Literal != "a" || p.peekToken.Literal != "+" {
+		t.Fatalf("peekN advanced the parser. cur=%q, peek=%q", p.curToken.Literal, p.peekToken.Literal)
+	}
+
+	// Advancing part of the way keeps the rest of the tokens already read in order
+	p.nextToken()
+	p.nextToken()
+	if tok := p.peekN(4); tok.Literal != "d" {
+		t.Errorf("peekN(4) after advancing wrong. expected=%q, got=%q", "d", tok.Literal)
+	}
+
+	for _, literal := range []string{"*", "c", ";", "d", ""} {
+		p.nextToken()
+		if p.curToken.Literal != literal {
+			t.Errorf("curToken wrong. expected=%q, got=%q", literal, p.curToken.Literal)
+		}
+	}
+
+	if len(p.comments) != 1 || p.comments[0].Text != "// comment" {
+		t.Errorf("comment read while looking ahead was lost. got=%v", p.comments)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("peekN(%d) did not panic", MAX_LOOKAHEAD+1)
+		}
+	}()
+	p.peekN(MAX_LOOKAHEAD + 1)
+}
+
+func TestInsertSemicolons(t *testing.T) {
+	// Checks that line breaks end statements wherever they could end in InsertSemicolons mode, and
+	// that statements sharing a line need semicolons
+
+	tests := []struct {
+		input    string
+		expected string
+		errors   []string
+	}{
+		{"let x = a\n-b", "let x = a;(-b)", nil},
+		{"let x = a +\nb\n- c", "let x = (a + b);(-c)", nil},
+		{"x[\n1\n]\ny", "(x[1])y", nil},
+		{"let m = match (x) {\n1 => a\n- b,\n_ => c\n}\nm",
+			"let m = match (x) { 1 => (a - b), _ => c };m", nil},
+		{"for (x in xs) {\nx\n-1\n}", "for (x in xs) x(-1)", nil},
+		{"let s = `a\nb` + c", "let s = (a\nb + c);", nil},
+		{"let x = 5; let y = 6", "let x = 5;let y = 6;", nil},
+		{"return a\n* b", "return a;", []string{"no prefix parse function for * found"}},
+		{"let x = 5 let y = 6", "let y = 6;",
+			[]string{"expected ; or a line break after the statement, got LET instead"}},
+		{"for (x in xs) { x } y", "",
+			[]string{"expected ; or a line break after the statement, got IDENT instead"}},
+	}
+
+	for _, tt := range tests {
+		p := NewWithMode(lexer.New(tt.input), InsertSemicolons)
+		program := p.ParseProgram()
+
+		errors := p.ErrorStrings()
+		if strings.Join(errors, "\n") != strings.Join(tt.errors, "\n") {
+			t.Errorf("errors wrong for %q. expected=%q, got=%q", tt.input, tt.errors, errors)
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("program wrong for %q. expected=%q, got=%q", tt.input, tt.expected,
+				program.String())
+		}
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	// Checks that the source between Pos() and End() of every node is the text it was parsed from,
+	// and that End() agrees with the offsets on lines and columns
+
+	input := "let x: int = -a[1:] * 2;\nfor (k, v in m) {\n\treturn match (k) { 1 => `a\nb`, _ => \"${v}!\" };\n}"
+
+	expected := []string{
+		input,
+		"let x: int = -a[1:] * 2",
+		"x",
+		"int",
+		"-a[1:] * 2",
+		"-a[1:]",
+		"a[1:]",
+		"a",
+		"1",
+		"2",
+		"for (k, v in m) {\n\treturn match (k) { 1 => `a\nb`, _ => \"${v}!\" };\n}",
+		"k",
+		"v",
+		"m",
+		"{\n\treturn match (k) { 1 => `a\nb`, _ => \"${v}!\" };\n}",
+		"return match (k) { 1 => `a\nb`, _ => \"${v}!\" }",
+		"match (k) { 1 => `a\nb`, _ => \"${v}!\" }",
+		"k",
+		"1",
+		"`a\nb`",
+		"\"${v}!\"",
+		"\"${",
+		"v",
+		"}!\"",
+	}
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	lines := strings.Split(input, "\n")
+
+	var actual []string
+	ast.Inspect(program, func(node ast.Node) bool {
+		pos, end := node.Pos(), node.End()
+		actual = append(actual, input[pos.Offset:end.Offset])
+
+		// The column past the end of the node is one more than the number of bytes on its last line
+		lineStart := len(strings.Join(lines[:end.Line-1], "\n"))
+		if end.Line > 1 {
+			lineStart++
+		}
+		if end.Column != end.Offset-lineStart+1 {
+			t.Errorf("End() of %T wrong. offset=%d, got=%s", node, end.Offset, end)
+		}
+
+		return true
+	})
+
+	if len(actual) != len(expected) {
+		t.Fatalf("wrong number of nodes. expected=%d, got=%d (%q)", len(expected), len(actual), actual)
+	}
+
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("source of node %d wrong. expected=%q, got=%q", i, expected[i], actual[i])
+		}
+	}
+}
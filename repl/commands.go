@@ -0,0 +1,138 @@
+// repl/commands.go
+
+package repl
+
+import (
+	"fmt"
+	"io"
+	"monkey/ast"
+	"monkey/lexer"
+	"monkey/parser"
+	"monkey/token"
+	"strings"
+)
+
+// Prefix that marks a line as a REPL command rather than monkey source
+const COMMAND_PREFIX = ":"
+
+type command struct {
+	// A colon-prefixed REPL command; `run` returns true if the REPL should exit
+
+	name  string
+	usage string
+	help  string
+	run   func(arg string, out io.Writer) bool
+}
+
+// The slice is initialized in init() since :help refers back to it
+var commands []command
+
+func init() {
+	commands = []command{
+		{"help", ":help", "show this message", runHelp},
+		{"quit", ":quit", "exit the REPL", runQuit},
+		{"tokens", ":tokens <expr>", "print the tokens produced by the lexer", runTokens},
+		{"ast", ":ast <expr>", "print the parsed AST as a typed tree", runAst},
+		{"env", ":env", "print the current bindings", runEnv},
+		{"reset", ":reset", "clear the current bindings", runReset},
+	}
+}
+
+func isCommand(line string) bool {
+	// Checks if a line of input is a REPL command
+
+	return strings.HasPrefix(strings.TrimSpace(line), COMMAND_PREFIX)
+}
+
+func runCommand(line string, out io.Writer) bool {
+	// Splits the line into a command name and its argument and dispatches to the matching command;
+	// returns true if the REPL should exit
+
+	line = strings.TrimPrefix(strings.TrimSpace(line), COMMAND_PREFIX)
+	name, arg, _ := strings.Cut(line, " ")
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(strings.TrimSpace(arg), out)
+		}
+	}
+
+	fmt.Fprintf(out, "unknown command %s%s, type %shelp for a list of commands\n", COMMAND_PREFIX,
+		name, COMMAND_PREFIX)
+
+	return false
+}
+
+func runHelp(arg string, out io.Writer) bool {
+	// Prints the usage of every command
+
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-16s %s\n", cmd.usage, cmd.help)
+	}
+
+	return false
+}
+
+func runQuit(arg string, out io.Writer) bool {
+	// Exits the REPL
+
+	return true
+}
+
+func runTokens(arg string, out io.Writer) bool {
+	// Prints the tokens output by the lexer until encountering an EOF
+
+	printTokens(arg, out)
+
+	return false
+}
+
+func runAst(arg string, out io.Writer) bool {
+	// Parses the argument and prints the resulting AST, or the parser errors if there are any
+
+	p := parser.New(lexer.New(arg))
+	program := p.ParseProgram()
+
+	if len(p.Errors()) != 0 {
+		printParserErrors(out, arg, p.Errors())
+		return false
+	}
+
+	fmt.Fprintln(out, ast.Dump(program))
+
+	return false
+}
+
+func runEnv(arg string, out io.Writer) bool {
+	// There is no evaluator yet, so there are no bindings to show
+
+	fmt.Fprintln(out, "no bindings: the REPL does not evaluate input yet")
+
+	return false
+}
+
+func runReset(arg string, out io.Writer) bool {
+	// See runEnv
+
+	fmt.Fprintln(out, "no bindings: the REPL does not evaluate input yet")
+
+	return false
+}
+
+func printTokens(input string, out io.Writer) {
+	// Prints every token in the input on its own line
+
+	l := lexer.New(input)
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		fmt.Fprintf(out, "%+v\n", tok)
+	}
+}
+
+func printParserErrors(out io.Writer, source string, errors []parser.ParserError) {
+	// Prints each parser error followed by the offending line of the source
+
+	for _, err := range errors {
+		fmt.Fprint(out, err.Format(source))
+	}
+}
@@ -0,0 +1,93 @@
+// repl/complete.go
+
+package repl
+
+import (
+	"monkey/token"
+	"sort"
+	"strings"
+)
+
+type completer struct {
+	// Suggests completions for the word under the cursor from keywords, REPL commands, and
+	// whatever names `bound` returns, e.g. the keys of the environment once there is one
+
+	bound func() []string
+}
+
+func newCompleter(bound func() []string) *completer {
+	// Creates a new completer; `bound` may be nil
+
+	return &completer{bound: bound}
+}
+
+func (c *completer) candidates(word string) []string {
+	// Returns every known name starting with `word` in sorted order without duplicates
+
+	var names []string
+
+	if strings.HasPrefix(word, COMMAND_PREFIX) {
+		for _, cmd := range commands {
+			names = append(names, COMMAND_PREFIX+cmd.name)
+		}
+	} else {
+		names = append(names, token.Keywords()...)
+		if c.bound != nil {
+			names = append(names, c.bound()...)
+		}
+	}
+
+	seen := make(map[string]bool)
+	matches := []string{}
+
+	for _, name := range names {
+		if strings.HasPrefix(name, word) && !seen[name] {
+			seen[name] = true
+			matches = append(matches, name)
+		}
+	}
+
+	sort.Strings(matches)
+
+	return matches
+}
+
+func wordStart(line []rune, pos int) int {
+	// Returns the index where the word ending at `pos` begins; a leading colon at the start of the
+	// line is part of the word so that commands can be completed
+
+	start := pos
+	for start > 0 && isWordChar(line[start-1]) {
+		start--
+	}
+
+	if start == 1 && string(line[0]) == COMMAND_PREFIX {
+		start = 0
+	}
+
+	return start
+}
+
+func isWordChar(r rune) bool {
+	// Mirrors the lexer's definition of identifier chars
+
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || r == '_'
+}
+
+func commonPrefix(words []string) string {
+	// Returns the longest prefix shared by all words
+
+	if len(words) == 0 {
+		return ""
+	}
+
+	prefix := words[0]
+
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	return prefix
+}
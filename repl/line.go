@@ -0,0 +1,491 @@
+// repl/line.go
+
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Name of the history file stored in the user's home directory
+	HISTORY_FILE = ".monkey_history"
+
+	// Maximum number of history entries kept in memory and loaded from disk
+	HISTORY_LIMIT = 1000
+)
+
+// Control characters delivered by the terminal in raw mode
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyBackspace = 8
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyCtrlK     = 11
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// Returned by the line editor when Ctrl-C is pressed, so the REPL can drop whatever it was reading
+var errInterrupted = errors.New("interrupted")
+
+type lineReader interface {
+	// Reads a single line of input after displaying the prompt
+
+	ReadLine(prompt string) (string, error)
+	Close() error
+}
+
+func newLineReader(in io.Reader, out io.Writer) lineReader {
+	// Uses the line editor when reading from an interactive terminal, otherwise falls back to a plain
+	// scanner so piped input keeps working
+
+	if f, ok := in.(*os.File); ok && isTerminal(f.Fd()) {
+		editor := newLineEditor(in, out)
+		editor.fd = f.Fd()
+		editor.historyPath = historyPath()
+		editor.loadHistory()
+		editor.completer = newCompleter(nil)
+		return editor
+	}
+
+	return &scannerReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+type scannerReader struct {
+	// Reads lines without any editing support
+
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (s *scannerReader) ReadLine(prompt string) (string, error) {
+	// Prints the prompt and reads from the input until encountering a newline
+
+	fmt.Fprint(s.out, prompt)
+
+	if !s.scanner.Scan() {
+		if err := s.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+
+	return s.scanner.Text(), nil
+}
+
+func (s *scannerReader) Close() error {
+	// Implements the lineReader interface
+
+	return nil
+}
+
+type lineEditor struct {
+	// A minimal readline-style editor with cursor movement and persistent history
+
+	in  *bufio.Reader
+	out io.Writer
+
+	// File descriptor of the terminal; raw mode is only enabled while a line is being read so that
+	// program output is printed normally
+	fd uintptr
+
+	// The line currently being edited and the cursor position within it
+	buf []rune
+	pos int
+
+	history     []string
+	historyPath string
+
+	// Supplies candidates when Tab is pressed; completion is disabled if nil
+	completer *completer
+}
+
+func newLineEditor(in io.Reader, out io.Writer) *lineEditor {
+	// Creates a new line editor without touching the terminal or the history file
+
+	return &lineEditor{in: bufio.NewReader(in), out: out, fd: ^uintptr(0)}
+}
+
+func historyPath() string {
+	// Returns the location of the history file, or an empty string if there is no home directory
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, HISTORY_FILE)
+}
+
+func (e *lineEditor) loadHistory() {
+	// Reads previously entered lines from the history file; a missing file is not an error
+
+	if e.historyPath == "" {
+		return
+	}
+
+	data, err := os.ReadFile(e.historyPath)
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		e.addHistory(line)
+	}
+}
+
+func (e *lineEditor) addHistory(line string) bool {
+	// Appends a line to the in-memory history, skipping blank lines and immediate repeats
+
+	if strings.TrimSpace(line) == "" {
+		return false
+	}
+
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return false
+	}
+
+	e.history = append(e.history, line)
+
+	if len(e.history) > HISTORY_LIMIT {
+		e.history = e.history[len(e.history)-HISTORY_LIMIT:]
+	}
+
+	return true
+}
+
+func (e *lineEditor) saveHistory(line string) {
+	// Appends a single entry to the history file so history survives between sessions, then trims
+	// the file to the last HISTORY_LIMIT entries so it doesn't grow forever
+
+	if e.historyPath == "" {
+		return
+	}
+
+	f, err := os.OpenFile(e.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+
+	_, err = fmt.Fprintln(f, line)
+	if f.Close() != nil || err != nil {
+		return
+	}
+
+	data, err := os.ReadFile(e.historyPath)
+	if err != nil {
+		return
+	}
+
+	// Other sessions append to the same file, so it's trimmed as found rather than rewritten from
+	// this session's history
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) > HISTORY_LIMIT {
+		lines = lines[len(lines)-HISTORY_LIMIT:]
+		os.WriteFile(e.historyPath, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+	}
+}
+
+func (e *lineEditor) ReadLine(prompt string) (string, error) {
+	// Reads a line of input in raw mode, handling editing keys until Enter is pressed
+
+	if e.fd != ^uintptr(0) {
+		restore, err := makeRaw(e.fd)
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+
+	line, err := e.edit(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	if e.addHistory(line) {
+		e.saveHistory(line)
+	}
+
+	return line, nil
+}
+
+func (e *lineEditor) edit(prompt string) (string, error) {
+	// Processes keys one at a time and redraws the line after every change
+
+	e.buf = e.buf[:0]
+	e.pos = 0
+
+	// Index into the history while navigating with the arrow keys; the line being typed is kept in
+	// `pending` so it can be restored when navigating back down
+	histIndex := len(e.history)
+	pending := ""
+
+	e.refresh(prompt)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, keyLineFeed:
+			fmt.Fprint(e.out, "\r\n")
+			return string(e.buf), nil
+		case keyCtrlC:
+			// Abandon the current line, along with any unfinished input it was continuing
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteForward()
+		case keyCtrlA:
+			e.pos = 0
+		case keyCtrlE:
+			e.pos = len(e.buf)
+		case keyCtrlB:
+			e.moveLeft()
+		case keyCtrlF:
+			e.moveRight()
+		case keyCtrlK:
+			e.buf = e.buf[:e.pos]
+		case keyCtrlU:
+			e.buf = append(e.buf[:0], e.buf[e.pos:]...)
+			e.pos = 0
+		case keyBackspace, keyDelete:
+			e.deleteBackward()
+		case keyCtrlP:
+			histIndex, pending = e.historyPrev(histIndex, pending)
+		case keyCtrlN:
+			histIndex = e.historyNext(histIndex, pending)
+		case keyTab:
+			e.completeWord()
+		case keyEscape:
+			final, param := e.readEscape()
+
+			switch final {
+			case 'A':
+				histIndex, pending = e.historyPrev(histIndex, pending)
+			case 'B':
+				histIndex = e.historyNext(histIndex, pending)
+			case 'C':
+				e.moveRight()
+			case 'D':
+				e.moveLeft()
+			case 'H':
+				e.pos = 0
+			case 'F':
+				e.pos = len(e.buf)
+			case '~':
+				// Keys like Home and Delete send `ESC [ <n> ~`, where only the number tells them apart
+				switch param {
+				case "1", "7":
+					e.pos = 0
+				case "3":
+					e.deleteForward()
+				case "4", "8":
+					e.pos = len(e.buf)
+				}
+			}
+		default:
+			if r >= ' ' {
+				e.insert(r)
+			}
+		}
+
+		e.refresh(prompt)
+	}
+}
+
+func (e *lineEditor) readEscape() (rune, string) {
+	// Reads the remainder of an escape sequence like `ESC [ A` or `ESC [ 3 ~` and returns its final
+	// char along with the parameters before it, e.g. "3"; the final char is 0 if the sequence is
+	// malformed
+
+	r, _, err := e.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return 0, ""
+	}
+
+	var param strings.Builder
+
+	for {
+		r, _, err = e.in.ReadRune()
+		if err != nil {
+			return 0, ""
+		}
+
+		// Parameter bytes are digits and semicolons; anything else terminates the sequence
+		if (r < '0' || r > '9') && r != ';' {
+			return r, param.String()
+		}
+
+		param.WriteRune(r)
+	}
+}
+
+func (e *lineEditor) completeWord() {
+	// Completes the word before the cursor as far as it is unambiguous; if it can't be extended,
+	// lists every candidate below the line
+
+	if e.completer == nil {
+		return
+	}
+
+	start := wordStart(e.buf, e.pos)
+	word := string(e.buf[start:e.pos])
+
+	if word == "" {
+		return
+	}
+
+	matches := e.completer.candidates(word)
+	if len(matches) == 0 {
+		return
+	}
+
+	prefix := commonPrefix(matches)
+
+	if len(prefix) > len(word) {
+		for _, r := range prefix[len(word):] {
+			e.insert(r)
+		}
+		return
+	}
+
+	if len(matches) > 1 {
+		fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(matches, "  "))
+	}
+}
+
+func (e *lineEditor) insert(r rune) {
+	// Inserts a char at the cursor position and advances the cursor
+
+	e.buf = append(e.buf, 0)
+	copy(e.buf[e.pos+1:], e.buf[e.pos:])
+	e.buf[e.pos] = r
+	e.pos++
+}
+
+func (e *lineEditor) deleteBackward() {
+	// Removes the char before the cursor
+
+	if e.pos == 0 {
+		return
+	}
+
+	e.buf = append(e.buf[:e.pos-1], e.buf[e.pos:]...)
+	e.pos--
+}
+
+func (e *lineEditor) deleteForward() {
+	// Removes the char under the cursor
+
+	if e.pos >= len(e.buf) {
+		return
+	}
+
+	e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+}
+
+func (e *lineEditor) moveLeft() {
+	// Moves the cursor one char to the left
+
+	if e.pos > 0 {
+		e.pos--
+	}
+}
+
+func (e *lineEditor) moveRight() {
+	// Moves the cursor one char to the right
+
+	if e.pos < len(e.buf) {
+		e.pos++
+	}
+}
+
+func (e *lineEditor) historyPrev(index int, pending string) (int, string) {
+	// Replaces the line with the previous history entry, remembering the line being typed when
+	// leaving it
+
+	if index == 0 {
+		return index, pending
+	}
+
+	if index == len(e.history) {
+		pending = string(e.buf)
+	}
+
+	index--
+	e.setLine(e.history[index])
+
+	return index, pending
+}
+
+func (e *lineEditor) historyNext(index int, pending string) int {
+	// Replaces the line with the next history entry, or the line being typed past the newest entry
+
+	if index >= len(e.history) {
+		return index
+	}
+
+	index++
+
+	if index == len(e.history) {
+		e.setLine(pending)
+	} else {
+		e.setLine(e.history[index])
+	}
+
+	return index
+}
+
+func (e *lineEditor) setLine(line string) {
+	// Replaces the whole line and moves the cursor to the end
+
+	e.buf = append(e.buf[:0], []rune(line)...)
+	e.pos = len(e.buf)
+}
+
+func (e *lineEditor) refresh(prompt string) {
+	// Redraws the prompt and line, clears anything left over from the previous draw, then moves the
+	// cursor back to its position
+
+	var out strings.Builder
+
+	out.WriteString("\r")
+	out.WriteString(prompt)
+	out.WriteString(string(e.buf))
+	out.WriteString("\x1b[K")
+
+	if back := len(e.buf) - e.pos; back > 0 {
+		fmt.Fprintf(&out, "\x1b[%dD", back)
+	}
+
+	fmt.Fprint(e.out, out.String())
+}
+
+func (e *lineEditor) Close() error {
+	// Implements the lineReader interface; history is written as it is entered, so there is nothing
+	// left to flush
+
+	return nil
+}
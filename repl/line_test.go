@@ -0,0 +1,185 @@
+// repl/line_test.go
+
+package repl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLineEditorKeys(t *testing.T) {
+	// Feeds raw key sequences into the editor and compares the resulting lines
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 5;\r", "let x = 5;"},
+		{"abc\x01x\r", "xabc"},
+		{"abc\x01\x05x\r", "abcx"},
+		{"abc\x1b[D\x1b[Dx\r", "axbc"},
+		{"abc\x1b[D\x1b[C\x1b[Cx\r", "abcx"},
+		{"abc\x7f\x7fx\r", "ax"},
+		{"abc\x01\x1b[3~\r", "bc"},
+		{"abc\x1b[1~x\x1b[4~y\r", "xabcy"},
+		{"abc\x1b[D\x1b[2~\x1b[5~\x1b[6~x\r", "abxc"},
+		{"abcdef\x1b[D\x1b[D\x1b[D\x0b\r", "abc"},
+		{"abcdef\x1b[D\x1b[D\x1b[D\x15\r", "def"},
+	}
+
+	for _, tt := range tests {
+		e := newLineEditor(strings.NewReader(tt.input), io.Discard)
+
+		line, err := e.ReadLine(PROMPT)
+		if err != nil {
+			t.Fatalf("ReadLine(%q) returned error: %s", tt.input, err)
+		}
+
+		if line != tt.expected {
+			t.Errorf("ReadLine(%q) wrong. expected=%q, got=%q", tt.input, tt.expected, line)
+		}
+	}
+}
+
+func TestLineEditorHistory(t *testing.T) {
+	// Checks that previous lines can be recalled and that history persists to disk
+
+	path := filepath.Join(t.TempDir(), HISTORY_FILE)
+
+	input := "first\rsecond\r\x1b[A\x1b[A\r\x1b[A\x1b[B\r"
+
+	e := newLineEditor(strings.NewReader(input), io.Discard)
+	e.historyPath = path
+
+	expected := []string{"first", "second", "first", ""}
+
+	for i, want := range expected {
+		line, err := e.ReadLine(PROMPT)
+		if err != nil {
+			t.Fatalf("line %d: ReadLine returned error: %s", i, err)
+		}
+
+		if line != want {
+			t.Errorf("line %d wrong. expected=%q, got=%q", i, want, line)
+		}
+	}
+
+	if _, err := e.ReadLine(PROMPT); err != io.EOF {
+		t.Errorf("expected io.EOF at end of input. got=%v", err)
+	}
+
+	// A fresh editor should load what the previous one wrote
+	reloaded := newLineEditor(&bytes.Buffer{}, io.Discard)
+	reloaded.historyPath = path
+	reloaded.loadHistory()
+
+	if strings.Join(reloaded.history, ",") != "first,second,first" {
+		t.Errorf("history not persisted. got=%q", reloaded.history)
+	}
+}
+
+func TestLineEditorHistoryLimit(t *testing.T) {
+	// The history file is trimmed to the last HISTORY_LIMIT entries when a line is saved
+
+	path := filepath.Join(t.TempDir(), HISTORY_FILE)
+
+	var old strings.Builder
+	for i := 0; i < HISTORY_LIMIT+10; i++ {
+		fmt.Fprintf(&old, "old %d\n", i)
+	}
+	if err := os.WriteFile(path, []byte(old.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	e := newLineEditor(strings.NewReader("new\r"), io.Discard)
+	e.historyPath = path
+
+	if _, err := e.ReadLine(PROMPT); err != nil {
+		t.Fatalf("ReadLine returned error: %s", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != HISTORY_LIMIT {
+		t.Fatalf("history file has %d entries, expected %d", len(lines), HISTORY_LIMIT)
+	}
+
+	first := fmt.Sprintf("old %d", 11)
+	if lines[0] != first || lines[len(lines)-1] != "new" {
+		t.Errorf("history file wrong. expected %q to %q, got %q to %q", first, "new", lines[0],
+			lines[len(lines)-1])
+	}
+}
+
+func TestLineEditorCtrlC(t *testing.T) {
+	// Ctrl-C abandons the line being typed and the next line is read from scratch
+
+	e := newLineEditor(strings.NewReader("abc\x03def\r"), io.Discard)
+
+	if _, err := e.ReadLine(PROMPT); err != errInterrupted {
+		t.Fatalf("expected errInterrupted. got=%v", err)
+	}
+
+	line, err := e.ReadLine(PROMPT)
+	if err != nil {
+		t.Fatalf("ReadLine returned error: %s", err)
+	}
+
+	if line != "def" {
+		t.Errorf("line after Ctrl-C wrong. expected=%q, got=%q", "def", line)
+	}
+}
+
+func TestLineEditorCtrlDExits(t *testing.T) {
+	// Ctrl-D on an empty line ends input
+
+	e := newLineEditor(strings.NewReader("\x04"), io.Discard)
+
+	if _, err := e.ReadLine(PROMPT); err != io.EOF {
+		t.Errorf("expected io.EOF. got=%v", err)
+	}
+}
+
+func TestLineEditorCompletion(t *testing.T) {
+	// Pressing Tab completes keywords, commands, and bound names as far as they are unambiguous
+
+	tests := []struct {
+		input    string
+		bound    []string
+		expected string
+	}{
+		{"le\t x\r", nil, "let x"},
+		{"re\t\r", nil, "return"},
+		{"f\t\r", nil, "f"},
+		{"f\t\r", []string{"foobar"}, "f"},
+		{"foo\t\r", []string{"foobar"}, "foobar"},
+		{"fo\t\r", []string{"foobar"}, "fo"},
+		{"x + cou\t\r", []string{"counter", "count"}, "x + count"},
+		{":q\t\r", nil, ":quit"},
+		{"zz\t\r", nil, "zz"},
+	}
+
+	for _, tt := range tests {
+		bound := tt.bound
+		e := newLineEditor(strings.NewReader(tt.input), io.Discard)
+		e.completer = newCompleter(func() []string { return bound })
+
+		line, err := e.ReadLine(PROMPT)
+		if err != nil {
+			t.Fatalf("ReadLine(%q) returned error: %s", tt.input, err)
+		}
+
+		if line != tt.expected {
+			t.Errorf("ReadLine(%q) wrong. expected=%q, got=%q", tt.input, tt.expected, line)
+		}
+	}
+}
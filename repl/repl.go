@@ -3,36 +3,139 @@
 package repl
 
 import (
-	"bufio"
 	"fmt"
 	"io"
+	"monkey/ast"
 	"monkey/lexer"
-	"monkey/token"
+	"monkey/parser"
 )
 
 const PROMPT = ">> "
 
+// Shown instead of PROMPT in Verbose mode while the input so far is an unfinished statement, block,
+// or string
+const CONTINUATION_PROMPT = ".. "
+
+// Controls what the REPL prints for each line of input; the zero value prints only the tokens, like
+// the REPL from the lexer chapter of the book
+type Mode uint
+
+const (
+	// Also print the parsed program as reconstructed by String(), or the parser errors
+	Verbose Mode = 1 << iota
+)
+
 func Start(in io.Reader, out io.Writer) {
-	// Starts the REPL
+	// Starts the REPL printing only tokens
 
-	scanner := bufio.NewScanner(in)
+	StartWithMode(in, out, 0)
+}
 
-	for {
-		fmt.Fprint(out, PROMPT)
+func StartWithMode(in io.Reader, out io.Writer, mode Mode) {
+	// Starts the REPL with optional output enabled
 
+	// Interactive terminals get line editing and history, anything else is read line by line
+	reader := newLineReader(in, out)
+	defer reader.Close()
+
+	s := newSession()
+
+	for {
 		// Read from the input until encountering a newline
-		scanned := scanner.Scan()
-		if !scanned {
+		line, err := reader.ReadLine(PROMPT)
+		if err == errInterrupted {
+			continue
+		}
+		if err != nil {
 			return
 		}
 
-		// Pass the read line into an instance of the lexer
-		line := scanner.Text()
-		l := lexer.New(line)
+		// Lines starting with a colon are REPL commands like `:help` or `:quit`
+		if isCommand(line) {
+			if quit := runCommand(line, out); quit {
+				return
+			}
+			continue
+		}
+
+		// Only the parser cares whether a statement is finished, so tokens-only mode lexes each line as
+		// soon as it's entered, as it always has
+		input := line
+		if mode&Verbose != 0 {
+			var ok bool
+			if input, ok = s.readContinuation(reader, line); !ok {
+				continue
+			}
+		}
 
 		// Print the tokens output by the lexer until encountering an EOF
-		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
-			fmt.Fprintf(out, "%+v\n", tok)
+		printTokens(input, out)
+
+		if mode&Verbose != 0 {
+			s.printProgram(input, out)
 		}
 	}
 }
+
+type session struct {
+	// The lexer and parser used for every input, which are reset rather than created anew each time
+
+	l *lexer.Lexer
+	p *parser.Parser
+}
+
+func newSession() *session {
+	// Creates a session whose parser hasn't been given any input yet
+
+	l := lexer.New("")
+	return &session{l: l, p: parser.New(l)}
+}
+
+func (s *session) parse(input string) *ast.Program {
+	// Parses the input with the session's parser, dropping anything left from the previous input
+
+	s.l.Reset(input)
+	s.p.Reset(s.l)
+
+	return s.p.ParseProgram()
+}
+
+func (s *session) readContinuation(reader lineReader, input string) (string, bool) {
+	// Keeps reading lines onto the input while it ends in the middle of a statement, block, or
+	// string; any other error, such as one from typing `;`, or the end of the input stops it, leaving
+	// the errors to be reported, while Ctrl-C abandons the input and reports false
+
+	for s.incomplete(input) {
+		line, err := reader.ReadLine(CONTINUATION_PROMPT)
+		if err == errInterrupted {
+			return "", false
+		}
+		if err != nil {
+			break
+		}
+		input += "\n" + line
+	}
+
+	return input, true
+}
+
+func (s *session) incomplete(input string) bool {
+	// Checks if more input could finish the program parsed from the input so far
+
+	s.parse(input)
+
+	return s.p.Incomplete()
+}
+
+func (s *session) printProgram(input string, out io.Writer) {
+	// Prints the program parsed from the input, or the parser errors if there are any
+
+	program := s.parse(input)
+
+	if len(s.p.Errors()) != 0 {
+		printParserErrors(out, input, s.p.Errors())
+		return
+	}
+
+	fmt.Fprintf(out, "ast: %s\n", program.String())
+}
@@ -0,0 +1,138 @@
+// repl/repl_test.go
+
+package repl
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCommands(t *testing.T) {
+	// Runs each REPL command and checks that its output contains the expected text
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{":help", ":tokens <expr>"},
+		{":tokens let x", "{Type:LET Literal:let Pos:1:1 Length:3}\n{Type:IDENT Literal:x Pos:1:5 Length:1}\n"},
+		{":ast -a * b", "(InfixExpression *\n      (PrefixExpression -\n        (Identifier a))\n      (Identifier b))"},
+		{":ast let = 5;", "1:5: expected next token to be IDENT, got = instead\n    let = 5;\n        ^\n"},
+		{":env", "no bindings"},
+		{":bogus", "unknown command :bogus"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+
+		Start(strings.NewReader(tt.input+"\n"), &out)
+
+		if !strings.Contains(out.String(), tt.expected) {
+			t.Errorf("output of %q does not contain %q. got=%q", tt.input, tt.expected, out.String())
+		}
+	}
+}
+
+func TestQuitCommand(t *testing.T) {
+	// Input after :quit must not be processed
+
+	var out bytes.Buffer
+
+	Start(strings.NewReader(":quit\n:tokens let\n"), &out)
+
+	if strings.Contains(out.String(), "LET") {
+		t.Errorf("input after :quit was processed. got=%q", out.String())
+	}
+}
+
+func TestVerboseMode(t *testing.T) {
+	// Verbose mode prints the parsed program, or the parser errors, after the tokens
+
+	tests := []struct {
+		input    string
+		mode     Mode
+		expected string
+	}{
+		{"-a * b", 0, "{Type:IDENT Literal:b Pos:1:6 Length:1}\n" + PROMPT},
+		{"-a * b", Verbose, "{Type:IDENT Literal:b Pos:1:6 Length:1}\nast: ((-a) * b)\n" + PROMPT},
+		{"let = 5;", Verbose, "{Type:; Literal:; Pos:1:8 Length:1}\n" +
+			"1:5: expected next token to be IDENT, got = instead\n    let = 5;\n        ^\n" + PROMPT},
+		// The parser is reused between inputs, so errors from one mustn't carry over to the next
+		{"let = 5;\n-a * b", Verbose, "{Type:IDENT Literal:b Pos:1:6 Length:1}\nast: ((-a) * b)\n" + PROMPT},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+
+		StartWithMode(strings.NewReader(tt.input+"\n"), &out, tt.mode)
+
+		if !strings.HasSuffix(out.String(), tt.expected) {
+			t.Errorf("output of %q does not end with %q. got=%q", tt.input, tt.expected, out.String())
+		}
+	}
+}
+
+func TestContinuation(t *testing.T) {
+	// Input that ends inside a block or string keeps reading lines with the continuation prompt until
+	// it is complete, or until another error makes more input pointless
+
+	tests := []struct {
+		input    string
+		prompts  string
+		expected string
+	}{
+		{"for (x in xs) {\nx;\n}", PROMPT + CONTINUATION_PROMPT + CONTINUATION_PROMPT,
+			"ast: for (x in xs) x\n"},
+		{"let s = `a\n\nb`;", PROMPT + CONTINUATION_PROMPT + CONTINUATION_PROMPT,
+			"ast: let s = a\n\nb;\n"},
+		{"let x =\n;", PROMPT + CONTINUATION_PROMPT, "2:1: no prefix parse function for ; found"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+
+		StartWithMode(strings.NewReader(tt.input+"\n"), &out, Verbose)
+
+		if !strings.HasPrefix(out.String(), tt.prompts+"{") {
+			t.Errorf("prompts for %q wrong. expected=%q, got=%q", tt.input, tt.prompts, out.String())
+		}
+
+		if !strings.Contains(out.String(), tt.expected) {
+			t.Errorf("output of %q does not contain %q. got=%q", tt.input, tt.expected, out.String())
+		}
+	}
+}
+
+func TestContinuationInterrupted(t *testing.T) {
+	// Ctrl-C at the continuation prompt drops the unfinished input instead of only clearing the line
+
+	s := newSession()
+	e := newLineEditor(strings.NewReader("x;\rlet y\x03"), io.Discard)
+
+	if input, ok := s.readContinuation(e, "for (x in xs) {"); ok {
+		t.Fatalf("unfinished input not abandoned. got=%q", input)
+	}
+}
+
+func TestContinuationTokensOnly(t *testing.T) {
+	// Without Verbose, an unfinished line is lexed right away instead of waiting for more input
+
+	var out bytes.Buffer
+
+	Start(strings.NewReader("let x = 1 +\n"), &out)
+
+	expected := PROMPT + "{Type:LET Literal:let Pos:1:1 Length:3}\n"
+	if !strings.HasPrefix(out.String(), expected) {
+		t.Errorf("output does not start with %q. got=%q", expected, out.String())
+	}
+
+	if strings.Contains(out.String(), CONTINUATION_PROMPT) {
+		t.Errorf("continuation prompt shown without Verbose. got=%q", out.String())
+	}
+
+	if !strings.Contains(out.String(), "{Type:+ Literal:+ Pos:1:11 Length:1}\n"+PROMPT) {
+		t.Errorf("tokens of the unfinished line not printed. got=%q", out.String())
+	}
+}
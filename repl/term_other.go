@@ -0,0 +1,20 @@
+// repl/term_other.go
+
+//go:build !linux
+
+package repl
+
+import "errors"
+
+func isTerminal(fd uintptr) bool {
+	// Raw terminal handling is only implemented for Linux; everywhere else the REPL falls back to
+	// plain line-buffered input
+
+	return false
+}
+
+func makeRaw(fd uintptr) (func(), error) {
+	// See isTerminal
+
+	return nil, errors.New("raw terminal mode not supported on this platform")
+}
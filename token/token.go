@@ -2,11 +2,68 @@
 
 package token
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position // Where the token starts in the input
+	Length  int      // Number of bytes of input the token spans
+}
+
+func (t Token) End() int {
+	// Returns the byte offset just past the end of the token, so input[t.Pos.Offset:t.End()] is
+	// the source text of the token
+
+	return t.Pos.Offset + t.Length
+}
+
+func (t Token) EndPosition() Position {
+	// Returns the position just past the end of the token; only strings can span lines, and their
+	// literals hold the text between the delimiters, or everything from the opening delimiter on if
+	// the string is unterminated
+
+	end := Position{Offset: t.End(), Line: t.Pos.Line, Column: t.Pos.Column + t.Length}
+
+	if i := strings.LastIndexByte(t.Literal, '\n'); i >= 0 {
+		opening := 1
+		if t.Type == ILLEGAL {
+			opening = 0
+		}
+
+		end.Line += strings.Count(t.Literal, "\n")
+		end.Column = t.Length - opening - i
+	}
+
+	return end
+}
+
+type Position struct {
+	// A location in the input; the offset starts at 0, the line and column at 1, and the column
+	// counts bytes
+
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	// Returns the position as "line:column"
+
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+func (p Position) IsValid() bool {
+	// Checks if the position was set; positions of hand-built tokens are left zeroed
+
+	return p.Line > 0
 }
 
 const (
@@ -15,8 +72,26 @@ const (
 	EOF     = "EOF"
 
 	// Identifiers & literals
-	IDENT = "IDENT" // variable & function names
-	INT   = "INT"
+	IDENT  = "IDENT" // variable & function names
+	INT    = "INT"
+	STRING = "STRING"
+
+	// A single character between single quotes, e.g. 'a' or '\n'
+	CHAR = "CHAR"
+
+	// Strings between backticks, which can contain quotes and never embed expressions or interpret
+	// escapes
+	RAW_STRING = "RAW_STRING"
+
+	// The pieces of a string with embedded expressions, e.g. `"a ${x} b ${y} c"` is lexed as the
+	// head `"a ${`, the tokens of x, the middle `} b ${`, the tokens of y, and the tail `} c"`; the
+	// literals hold only the text between the delimiters
+	STRING_HEAD   = "STRING_HEAD"
+	STRING_MIDDLE = "STRING_MIDDLE"
+	STRING_TAIL   = "STRING_TAIL"
+
+	// Comments run from `//` to the end of the line; the parser skips them unless asked to keep them
+	COMMENT = "COMMENT"
 
 	// Operators
 	ASSIGN   = "="
@@ -29,23 +104,35 @@ const (
 	LT = "<"
 	GT = ">"
 
+	FAT_ARROW = "=>" // Separates the pattern and value of a match arm
+	ARROW     = "->" // Precedes the return type in a function's type annotation
+
 	// Delimiters
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
 
 	LPAREN = "("
 	RPAREN = ")"
 	LBRACE = "{"
 	RBRACE = "}"
 
+	LBRACKET = "["
+	RBRACKET = "]"
+
 	// Keywords
 	FUNCTION = "FUNCTION"
 	LET      = "LET"
+	CONST    = "CONST"
 	TRUE     = "TRUE"
 	FALSE    = "FALSE"
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	MATCH    = "MATCH"
+	IMPORT   = "IMPORT"
+	FOR      = "FOR"
+	IN       = "IN"
 	EQ       = "EQ"
 	NOT_EQ   = "NOT_EQ"
 )
@@ -54,20 +141,73 @@ const (
 var keywords = map[string]TokenType{
 	"fn":     FUNCTION,
 	"let":    LET,
+	"const":  CONST,
 	"true":   TRUE,
 	"false":  FALSE,
 	"if":     IF,
 	"else":   ELSE,
 	"return": RETURN,
+	"match":  MATCH,
+	"import": IMPORT,
+	"for":    FOR,
+	"in":     IN,
 }
 
+// Guards keywords, which RegisterKeyword can add to while other goroutines are lexing
+var keywordsMu sync.RWMutex
+
 func LookupIdent(ident string) TokenType {
 	// Checks if the identifier is in the dictionary of keywords; if so, returns its corresponding
 	// token; otherwise, returns the user-defined identifier
 
+	keywordsMu.RLock()
+	defer keywordsMu.RUnlock()
+
 	if tok, ok := keywords[ident]; ok {
 		return tok
 	}
 
 	return IDENT
 }
+
+func RegisterKeyword(literal string, t TokenType) {
+	// Makes the lexer produce tokens of type `t` for `literal` instead of identifiers, so dialects
+	// can add keywords like `while` without editing this package; meant to be called during
+	// initialization, and panics if the literal isn't a valid identifier or is already a keyword
+
+	if literal == "" {
+		panic("token: RegisterKeyword called with an empty literal")
+	}
+
+	for _, ch := range literal {
+		if !('a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_') {
+			panic(fmt.Sprintf("token: keyword %q is not a valid identifier", literal))
+		}
+	}
+
+	keywordsMu.Lock()
+	defer keywordsMu.Unlock()
+
+	if _, ok := keywords[literal]; ok {
+		panic(fmt.Sprintf("token: keyword %q is already registered", literal))
+	}
+
+	keywords[literal] = t
+}
+
+func Keywords() []string {
+	// Returns the literals of every keyword in sorted order, e.g. for completion in the REPL
+
+	keywordsMu.RLock()
+	defer keywordsMu.RUnlock()
+
+	literals := make([]string, 0, len(keywords))
+
+	for literal := range keywords {
+		literals = append(literals, literal)
+	}
+
+	sort.Strings(literals)
+
+	return literals
+}
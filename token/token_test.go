@@ -0,0 +1,48 @@
+// token/token_test.go
+
+package token
+
+import "testing"
+
+func TestRegisterKeyword(t *testing.T) {
+	// A registered keyword is looked up like a built-in one and listed by Keywords()
+
+	const WHILE = "WHILE"
+
+	if LookupIdent("while") != IDENT {
+		t.Fatalf("while is already a keyword")
+	}
+
+	RegisterKeyword("while", WHILE)
+
+	if tok := LookupIdent("while"); tok != WHILE {
+		t.Errorf("LookupIdent(%q) wrong. expected=%q, got=%q", "while", WHILE, tok)
+	}
+
+	found := false
+	for _, keyword := range Keywords() {
+		found = found || keyword == "while"
+	}
+
+	if !found {
+		t.Errorf("Keywords() does not include while. got=%q", Keywords())
+	}
+}
+
+func TestRegisterKeywordPanics(t *testing.T) {
+	// Invalid and duplicate keywords are rejected
+
+	tests := []string{"", "let", "two words", "x1"}
+
+	for _, literal := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterKeyword(%q) did not panic", literal)
+				}
+			}()
+
+			RegisterKeyword(literal, "BAD")
+		}()
+	}
+}
@@ -0,0 +1,308 @@
+// types/types.go
+
+package types
+
+import (
+	"fmt"
+	"monkey/ast"
+	"monkey/token"
+	"sort"
+)
+
+// The type of a value as far as it can be worked out without running the program
+type Type string
+
+const (
+	UNKNOWN Type = "unknown" // Anything that can't be inferred, which is never reported
+	INT     Type = "int"
+	BOOL    Type = "bool"
+	STRING  Type = "string"
+)
+
+type Error struct {
+	// A probable type error found in a program
+
+	Pos     token.Position
+	Message string
+}
+
+func (e Error) Error() string {
+	// Returns the error as "line:column: message"
+
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+}
+
+type checker struct {
+	// Tracks the inferred types of the bindings currently in scope while walking a program
+
+	errors []Error
+	scope  map[string]Type
+}
+
+func Check(program *ast.Program) []Error {
+	// Infers the types of expressions and let bindings and returns the probable type errors sorted by
+	// position; expressions whose type can't be inferred are assumed to be fine
+
+	c := &checker{scope: make(map[string]Type)}
+
+	c.statements(program.Statements)
+
+	sort.SliceStable(c.errors, func(i, j int) bool {
+		a, b := c.errors[i].Pos, c.errors[j].Pos
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+
+	return c.errors
+}
+
+func (c *checker) report(pos token.Position, format string, args ...any) {
+	// Records an error
+
+	c.errors = append(c.errors, Error{Pos: pos, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) statements(statements []ast.Statement) {
+	// Checks a sequence of statements
+
+	for _, s := range statements {
+		c.statement(s)
+	}
+}
+
+func (c *checker) statement(s ast.Statement) {
+	// Checks a single statement, binding the names it declares
+
+	switch s := s.(type) {
+	case *ast.LetStatement:
+		// The value is checked first since it can still refer to an earlier binding of the same name
+		t := c.expression(s.Value)
+		if s.Type != nil {
+			t = c.annotation(s, t)
+		}
+		c.scope[s.Name.Value] = t
+	case *ast.ReturnStatement:
+		c.expression(s.ReturnValue)
+	case *ast.ExpressionStatement:
+		c.expression(s.Expression)
+	case *ast.ImportStatement:
+		c.scope[s.Name.Value] = UNKNOWN
+	case *ast.ForStatement:
+		if t := c.expression(s.Iterable); t == INT || t == BOOL {
+			c.report(s.Iterable.Pos(), "cannot loop over %s", t)
+		}
+		c.block(s.Body, s.Key, s.Value)
+	case *ast.BlockStatement:
+		c.block(s)
+	}
+}
+
+func (c *checker) annotation(s *ast.LetStatement, value Type) Type {
+	// Checks the value of a let statement against its type annotation and returns the annotated
+	// type, which takes precedence over the inferred one
+
+	var annotated Type
+	switch s.Type.Value {
+	case "int":
+		annotated = INT
+	case "bool":
+		annotated = BOOL
+	case "string":
+		annotated = STRING
+	default:
+		c.report(s.Type.Token.Pos, "unknown type %s", s.Type.Value)
+		return UNKNOWN
+	}
+
+	if value != UNKNOWN && value != annotated {
+		c.report(s.Value.Pos(), "cannot use %s value as %s in let %s", value, annotated,
+			s.Name.Value)
+	}
+
+	return annotated
+}
+
+func (c *checker) block(b *ast.BlockStatement, names ...*ast.Identifier) {
+	// Checks a block in a scope of its own, with `names` bound to values of unknown type
+
+	outer := c.scope
+
+	c.scope = make(map[string]Type, len(outer))
+	for name, t := range outer {
+		c.scope[name] = t
+	}
+
+	for _, name := range names {
+		if name != nil {
+			c.scope[name.Value] = UNKNOWN
+		}
+	}
+
+	c.statements(b.Statements)
+
+	c.scope = outer
+}
+
+func (c *checker) expression(e ast.Expression) Type {
+	// Returns the type of an expression, reporting any type errors within it
+
+	switch e := e.(type) {
+	case *ast.IntegerLiteral:
+		return INT
+	case *ast.CharLiteral:
+		// Characters are their code points, so they can be compared and offset like any integer
+		return INT
+	case *ast.StringLiteral:
+		return STRING
+	case *ast.InterpolatedString:
+		// The embedded expressions can have any type since they're converted to strings
+		for _, part := range e.Parts {
+			c.expression(part)
+		}
+		return STRING
+	case *ast.Identifier:
+		if t, ok := c.scope[e.Value]; ok {
+			return t
+		}
+		return UNKNOWN
+	case *ast.PrefixExpression:
+		right := c.expression(e.Right)
+
+		if e.Operator == "!" {
+			// Any value can be negated according to its truthiness
+			return BOOL
+		}
+
+		if right != UNKNOWN && right != INT {
+			c.report(e.Token.Pos, "operator %s not defined on %s", e.Operator, right)
+		}
+		return INT
+	case *ast.InfixExpression:
+		return c.infix(e)
+	case *ast.IndexExpression:
+		left := c.indexed(e.Token.Pos, c.expression(e.Left))
+		c.index(left, e.Index)
+		return elementType(left)
+	case *ast.SliceExpression:
+		left := c.indexed(e.Token.Pos, c.expression(e.Left))
+		c.index(left, e.Low)
+		c.index(left, e.High)
+		return left
+	case *ast.MatchExpression:
+		return c.match(e)
+	}
+
+	return UNKNOWN
+}
+
+func (c *checker) infix(e *ast.InfixExpression) Type {
+	// Returns the type of an infix expression; arithmetic and ordering need integers, except that
+	// `+` also joins strings, and equality needs both sides to have the same type
+
+	left, right := c.expression(e.Left), c.expression(e.Right)
+
+	result := INT
+	switch e.Operator {
+	case "<", ">", "==", "!=":
+		result = BOOL
+	case "+":
+		if left == STRING || right == STRING {
+			result = STRING
+		}
+	}
+
+	// Without both types, the result is still known unless `+` could be adding either
+	if left == UNKNOWN || right == UNKNOWN {
+		if e.Operator == "+" && left == UNKNOWN && right == UNKNOWN {
+			return UNKNOWN
+		}
+		return result
+	}
+
+	if left != right {
+		c.report(e.Token.Pos, "mismatched types %s and %s for %s", left, right, e.Operator)
+		return UNKNOWN
+	}
+
+	switch e.Operator {
+	case "==", "!=":
+	case "+":
+		if left != INT && left != STRING {
+			c.report(e.Token.Pos, "operator %s not defined on %s", e.Operator, left)
+		}
+	default:
+		if left != INT {
+			c.report(e.Token.Pos, "operator %s not defined on %s", e.Operator, left)
+		}
+	}
+
+	return result
+}
+
+func (c *checker) indexed(pos token.Position, left Type) Type {
+	// Reports values of type `left` that can't be indexed; returns the type to check the indices
+	// against, which is unknown after an error so it's only reported once
+
+	if left == INT || left == BOOL {
+		c.report(pos, "cannot index %s", left)
+		return UNKNOWN
+	}
+
+	return left
+}
+
+func (c *checker) index(left Type, index ast.Expression) {
+	// Checks an index or slice bound of a value of type `left`; the bound may be nil in slices
+
+	if index == nil {
+		return
+	}
+
+	t := c.expression(index)
+
+	if left == STRING && t != UNKNOWN && t != INT {
+		c.report(index.Pos(), "string index must be int, got %s", t)
+	}
+}
+
+func (c *checker) match(e *ast.MatchExpression) Type {
+	// Returns the type of a match expression, which is the type all of its arms agree on; patterns
+	// that can never equal the subject are reported
+
+	subject := c.expression(e.Subject)
+
+	values := []ast.Expression{}
+
+	for _, arm := range e.Arms {
+		pattern := c.expression(arm.Pattern)
+		if subject != UNKNOWN && pattern != UNKNOWN && pattern != subject {
+			c.report(arm.Pattern.Pos(), "%s pattern can never match %s", pattern, subject)
+		}
+		values = append(values, arm.Value)
+	}
+
+	if e.Default != nil {
+		values = append(values, e.Default)
+	}
+
+	result := UNKNOWN
+	for i, value := range values {
+		t := c.expression(value)
+		if i == 0 {
+			result = t
+		} else if t != result {
+			result = UNKNOWN
+		}
+	}
+
+	return result
+}
+
+func elementType(t Type) Type {
+	// Returns the type of the elements of a value of type `t`
+
+	if t == STRING {
+		return STRING
+	}
+
+	return UNKNOWN
+}
@@ -0,0 +1,109 @@
+// types/types_test.go
+
+package types
+
+import (
+	"monkey/lexer"
+	"monkey/parser"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	// Compares the type errors reported for monkey input with the expected ones
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			`let x = 1; let s = "a" + "b"; x * 2 < 10 == !s; s[x:x + 1];`,
+			[]string{},
+		},
+		{
+			`let x = 1; let s = "a"; x + s;`,
+			[]string{"1:27: mismatched types int and string for +"},
+		},
+		{
+			`let s = "a"; -s; s - "b"; s < s;`,
+			[]string{
+				"1:14: operator - not defined on string",
+				"1:20: operator - not defined on string",
+				"1:29: operator < not defined on string",
+			},
+		},
+		{
+			`let b = 1 < 2; b + b; b == 1;`,
+			[]string{
+				"1:18: operator + not defined on bool",
+				"1:25: mismatched types bool and int for ==",
+			},
+		},
+		{
+			`let x = 5; x[0]; x[1:"a"]; "abc"["b"];`,
+			[]string{
+				"1:13: cannot index int",
+				"1:19: cannot index int",
+				"1:34: string index must be int, got string",
+			},
+		},
+		{
+			// Types flow through lets, while unknown names and loop variables never cause errors
+			`let y = "a" + 1; let z = y - 1; for (c in "abc") { c - 1; let y = 2; y * 2; } y - 1;`,
+			[]string{"1:13: mismatched types string and int for +"},
+		},
+		{
+			`let s = "a"; for (x in 5) { x; } for (k, v in s == s) {}`,
+			[]string{"1:24: cannot loop over int", "1:47: cannot loop over bool"},
+		},
+		{
+			`let s = match (1) { "a" => 1, 2 => 2, _ => 3 }; s - 1;`,
+			[]string{"1:21: string pattern can never match int"},
+		},
+		{
+			`let n: int = 1; let s: string = n; let b: bool = "a" == "b"; let f: float = 1; s - n;`,
+			[]string{
+				"1:33: cannot use int value as string in let s",
+				"1:69: unknown type float",
+				"1:82: mismatched types string and int for -",
+			},
+		},
+		{
+			`let t = match (1) { 1 => "a", _ => "b" }; t - 1;`,
+			[]string{"1:45: mismatched types string and int for -"},
+		},
+		{
+			`let c = 'a'; c + 1; c < 'z'; c + "b";`,
+			[]string{"1:32: mismatched types int and string for +"},
+		},
+		{
+			`let s = "n: ${1 - "a"}"; s - 1;`,
+			[]string{
+				"1:17: mismatched types int and string for -",
+				"1:28: mismatched types string and int for -",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser errors for %q: %v", tt.input, p.Errors())
+		}
+
+		errors := Check(program)
+
+		if len(errors) != len(tt.expected) {
+			t.Fatalf("wrong number of errors for %q. expected=%d, got=%d (%v)", tt.input,
+				len(tt.expected), len(errors), errors)
+		}
+
+		for i, err := range errors {
+			if err.Error() != tt.expected[i] {
+				t.Errorf("errors[%d] wrong for %q. expected=%q, got=%q", i, tt.input,
+					tt.expected[i], err.Error())
+			}
+		}
+	}
+}